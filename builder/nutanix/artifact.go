@@ -5,6 +5,12 @@ type Artifact struct {
 	Name string
 	UUID string
 	//VM   *driver.VirtualMachine
+
+	// StateData holds the images, templates and source template version of the build
+	StateData map[string]interface{}
+
+	// files are the local files exported by the build
+	files []string
 }
 
 // BuilderId will return the unique builder id
@@ -14,7 +20,7 @@ func (a *Artifact) BuilderId() string {
 
 // Files will return the files from the builder
 func (a *Artifact) Files() []string {
-	return []string{}
+	return a.files
 }
 
 // Id returns the UUID for the saved image
@@ -27,9 +33,9 @@ func (a *Artifact) String() string {
 	return a.Name
 }
 
-// State returns nothing important right now
+// State returns the build data stored under name
 func (a *Artifact) State(name string) interface{} {
-	return nil
+	return a.StateData[name]
 }
 
 // Destroy returns nothing important right now
@@ -55,7 +55,29 @@ func (b *Builder) Run(ctx context.Context, ui packersdk.Ui, hook packersdk.Hook)
 	// so we put it in the state bag to be used by the cleanup step
 	state.Put("ctx", ctx)
 
-	steps := []multistep.Step{
+	var steps []multistep.Step
+
+	// An explicit cluster_name or cluster_uuid overrides the cluster selector
+	if b.config.VmConfig.ClusterName == "" && b.config.VmConfig.ClusterUUID == "" {
+		steps = append(steps, &stepSelectCluster{
+			Config: &b.config,
+		})
+	}
+
+	steps = append(steps, &stepCheckConflicts{
+		Config: &b.config,
+	})
+
+	// Exported files go to the output directory, set up before the build so an existing
+	// directory fails the build early
+	if b.config.OutputDirectory != "" && (b.config.ImageExport || b.config.OvaConfig.Export) {
+		steps = append(steps, &commonsteps.StepOutputDir{
+			Force: b.config.PackerForce || b.config.ForceOutput,
+			Path:  b.config.OutputDirectory,
+		})
+	}
+
+	steps = append(steps,
 		&commonsteps.StepCreateCD{
 			Files:   b.config.CDConfig.CDFiles,
 			Content: b.config.CDConfig.CDContent,
@@ -68,6 +90,13 @@ func (b *Builder) Run(ctx context.Context, ui packersdk.Ui, hook packersdk.Hook)
 		&stepVNCBootCommand{
 			Config: &b.config,
 		},
+		&stepBootSwitch{
+			Config: &b.config,
+		},
+		&stepMediaChange{
+			Config: &b.config,
+			Stage:  NutanixIdentifierMediaChangeAfterBoot,
+		},
 		&stepWaitForIp{
 			Config: &b.config.WaitIpConfig,
 		},
@@ -76,15 +105,23 @@ func (b *Builder) Run(ctx context.Context, ui packersdk.Ui, hook packersdk.Hook)
 			SSHConfig: b.config.Comm.SSHConfigFunc(),
 			Host:      commHost(b.config.Comm.Host()),
 		},
+		&stepMediaChange{
+			Config: &b.config,
+			Stage:  NutanixIdentifierMediaChangeBeforeProvision,
+		},
 		new(commonsteps.StepProvision),
+		&stepMediaChange{
+			Config: &b.config,
+			Stage:  NutanixIdentifierMediaChangeAfterProvision,
+		},
 		&StepShutdown{
 			Command:             b.config.ShutdownCommand,
 			Timeout:             b.config.ShutdownTimeout,
 			DisableStopInstance: b.config.DisableStopInstance,
 		},
-	}
+	)
 
-	if b.config.Clean.Cdrom {
+	if b.config.Clean.enabled() {
 		steps = append(steps, &stepCleanVM{
 			Config: &b.config,
 		})
@@ -98,8 +135,13 @@ func (b *Builder) Run(ctx context.Context, ui packersdk.Ui, hook packersdk.Hook)
 
 	if b.config.ImageExport {
 		steps = append(steps, &stepExportImage{
-			VMName:    b.config.VMName,
-			ImageName: b.config.VmConfig.ImageName,
+			VMName:          b.config.VMName,
+			OutputDirectory: b.config.OutputDirectory,
+			Format:          b.config.ExportFormat,
+			Compression:     b.config.ExportCompression,
+			Retries:         b.config.ExportRetries,
+			Destination:     b.config.ExportDestination,
+			Parallelism:     b.config.ExportParallelism,
 		})
 	}
 
@@ -118,11 +160,20 @@ func (b *Builder) Run(ctx context.Context, ui packersdk.Ui, hook packersdk.Hook)
 
 	if b.config.OvaConfig.Export {
 		steps = append(steps, &StepExportOVA{
-			VMName:    b.config.VMName,
-			OvaConfig: b.config.OvaConfig,
+			VMName:          b.config.VMName,
+			OvaConfig:       b.config.OvaConfig,
+			OutputDirectory: b.config.OutputDirectory,
+			Retries:         b.config.ExportRetries,
+			Destination:     b.config.ExportDestination,
 		})
 	}
 
+	// Replaced templates and OVAs are deleted once everything else succeeded
+	if (b.config.TemplateConfig.Create && b.config.TemplateConfig.OnConflict == NutanixIdentifierOnConflictReplace) ||
+		(b.config.OvaConfig.Create && b.config.OvaConfig.OnConflict == NutanixIdentifierOnConflictReplace) {
+		steps = append(steps, &stepDeleteReplaced{})
+	}
+
 	b.runner = commonsteps.NewRunnerWithPauseFn(steps, b.config.PackerConfig, ui, state)
 	b.runner.Run(ctx, state)
 
@@ -130,14 +181,67 @@ func (b *Builder) Run(ctx context.Context, ui packersdk.Ui, hook packersdk.Hook)
 		return nil, rawErr.(error)
 	}
 
-	if imageUUID, ok := state.GetOk("image_uuid"); ok {
-		if imageUUID != nil {
-			artifact := &Artifact{
-				Name: b.config.ImageName,
-				UUID: imageUUID.([]imageArtefact)[0].uuid,
+	artifact := &Artifact{
+		StateData: map[string]interface{}{},
+	}
+
+	// Record the source template version so layered builds can chain templates
+	if sourceTemplate, ok := state.GetOk("source_template"); ok {
+		artifact.StateData["source_template_uuid"] = sourceTemplate.(*nutanixTemplate).UUID()
+		artifact.StateData["source_template_version"] = sourceTemplate.(*nutanixTemplate).VersionUUID()
+	}
+
+	// Record the cluster picked by the cluster selector
+	if cluster, ok := state.GetOk("selected_cluster"); ok {
+		artifact.StateData["cluster_name"] = cluster.(*clusterCapacity).name
+		artifact.StateData["cluster_uuid"] = cluster.(*clusterCapacity).uuid
+	}
+
+	if template, ok := state.GetOk("template"); ok {
+		artifact.Name = template.(*nutanixTemplate).Name()
+		artifact.UUID = template.(*nutanixTemplate).UUID()
+		artifact.StateData["template_uuid"] = template.(*nutanixTemplate).UUID()
+		artifact.StateData["template_version"] = template.(*nutanixTemplate).VersionUUID()
+	}
+
+	if imageUUID, ok := state.GetOk("image_uuid"); ok && imageUUID != nil {
+		imageList := imageUUID.([]imageArtefact)
+		imageNames := make([]string, 0, len(imageList))
+		imageUUIDs := make([]string, 0, len(imageList))
+		for _, image := range imageList {
+			imageNames = append(imageNames, image.name)
+			imageUUIDs = append(imageUUIDs, image.uuid)
+		}
+
+		artifact.Name = imageList[0].name
+		artifact.UUID = imageList[0].uuid
+		artifact.StateData["image_names"] = imageNames
+		artifact.StateData["image_uuids"] = imageUUIDs
+	}
+
+	// Exported files, their checksums and the export manifest are the local files of the artifact,
+	// and the object storage URLs of the files exported to the export destination
+	if exported, ok := state.GetOk("exported_files"); ok {
+		var exportURLs []string
+		for _, file := range exported.([]exportedFile) {
+			if file.URL != "" {
+				exportURLs = append(exportURLs, file.URL)
+				continue
 			}
-			return artifact, nil
+			path := exportPath(b.config.OutputDirectory, file.Name)
+			artifact.files = append(artifact.files, path, path+".sha256")
 		}
+
+		if len(artifact.files) > 0 {
+			artifact.files = append(artifact.files, exportPath(b.config.OutputDirectory, exportManifestName))
+		}
+		if len(exportURLs) > 0 {
+			artifact.StateData["export_urls"] = exportURLs
+		}
+	}
+
+	if artifact.UUID != "" {
+		return artifact, nil
 	}
 	return nil, nil
 }
@@ -12,6 +12,10 @@ import (
 // convergedV4ClientCache is the shared cache for V4 converged clients (session auth enabled).
 var convergedV4ClientCache = convergedv4.NewClientCache(v4.WithSessionAuth(true))
 
+// v4SDKClientCache is the shared cache for V4 SDK clients, used for the VM operations the
+// converged client does not expose yet.
+var v4SDKClientCache = v4.NewClientCache(v4.WithSessionAuth(true))
+
 // v4CacheParams implements types.CachedClientParams for the converged V4 client cache.
 type v4CacheParams struct {
 	endpoint string
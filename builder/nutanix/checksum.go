@@ -0,0 +1,134 @@
+package nutanix
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// checksumFilePrefix prefixes a source_image_checksum read from a checksum file.
+const checksumFilePrefix = "file:"
+
+// maxChecksumFileSize bounds the size of a downloaded checksum file.
+const maxChecksumFileSize = 10 * 1024 * 1024
+
+// bsdChecksumLine matches the lines of BSD style checksum files: SHA256 (name) = digest.
+var bsdChecksumLine = regexp.MustCompile(`^(\w+) \((.+)\) = ([0-9a-fA-F]+)$`)
+
+// checksumTypeFromDigest returns the checksum type of a hex digest from its length.
+func checksumTypeFromDigest(digest string) string {
+	switch len(digest) {
+	case 32:
+		return NutanixIdentifierChecksunTypeMD5
+	case 40:
+		return NutanixIdentifierChecksunTypeSHA1
+	case 64:
+		return NutanixIdentifierChecksunTypeSHA256
+	case 128:
+		return NutanixIdentifierChecksunTypeSHA512
+	}
+	return ""
+}
+
+// resolveChecksum returns the checksum type and hex digest of a source_image_checksum. A
+// file:<url or path> checksum is looked up in the checksum file for the entry of fileName, and
+// its type is guessed from the digest length when checksumType is empty.
+func resolveChecksum(ctx context.Context, checksum string, checksumType string, fileName string) (string, string, error) {
+	if !strings.HasPrefix(checksum, checksumFilePrefix) {
+		return checksumType, checksum, nil
+	}
+
+	src := strings.TrimPrefix(checksum, checksumFilePrefix)
+	content, err := readChecksumFile(ctx, src)
+	if err != nil {
+		return "", "", fmt.Errorf("error reading checksum file %s: %s", src, err)
+	}
+
+	digest, err := findChecksum(content, fileName)
+	if err != nil {
+		return "", "", fmt.Errorf("checksum file %s: %s", src, err)
+	}
+
+	if checksumType == "" {
+		checksumType = checksumTypeFromDigest(digest)
+		if checksumType == "" {
+			return "", "", fmt.Errorf("checksum file %s: unknown checksum type of %s", src, digest)
+		}
+	}
+
+	log.Printf("checksum of %s read from %s: %s %s", fileName, src, checksumType, digest)
+	return checksumType, digest, nil
+}
+
+// readChecksumFile reads a checksum file from an http(s) URL, a file:// URL or a local path.
+func readChecksumFile(ctx context.Context, src string) ([]byte, error) {
+	u, err := url.Parse(src)
+	if err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, maxChecksumFileSize))
+	}
+
+	if err == nil && u.Scheme == "file" {
+		src = u.Path
+	}
+	return os.ReadFile(src)
+}
+
+// findChecksum returns the digest of fileName in a checksum file, in GNU (digest  name) or BSD
+// (TYPE (name) = digest) format. A file holding a single bare digest applies to any file.
+func findChecksum(content []byte, fileName string) (string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	for _, line := range lines {
+		var digest, name string
+		if m := bsdChecksumLine.FindStringSubmatch(line); m != nil {
+			name, digest = m[2], m[3]
+		} else if fields := strings.Fields(line); len(fields) >= 2 {
+			// Binary mode entries are prefixed with *
+			digest, name = fields[0], strings.TrimPrefix(strings.Join(fields[1:], " "), "*")
+		} else {
+			continue
+		}
+
+		if path.Base(name) == fileName {
+			return digest, nil
+		}
+	}
+
+	if len(lines) == 1 && len(strings.Fields(lines[0])) == 1 {
+		return lines[0], nil
+	}
+	return "", fmt.Errorf("no checksum found for %s", fileName)
+}
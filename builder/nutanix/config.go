@@ -1,4 +1,4 @@
-//go:generate packer-sdc mapstructure-to-hcl2 -type Config,Category,ClusterConfig,VmConfig,VmDisk,VmNIC,GPU,OvaConfig,TemplateConfig,VTPM,VmClean
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config,Category,ClusterConfig,VmConfig,VmDisk,VmNIC,GPU,OvaConfig,TemplateConfig,VTPM,VmClean,BootSwitch,MediaChange,TemplateGuestCustomization,ExportDestination,ImageFilter,ImagePlacementPolicy,ClusterSelector
 
 package nutanix
 
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/packer-plugin-sdk/bootcommand"
@@ -36,11 +38,83 @@ const (
 	// NutanixIdentifierBootPriorityCDROM is a resource identifier identifying the boot priority as cdrom for virtual machines.
 	NutanixIdentifierBootPriorityCDROM string = "cdrom"
 
+	// NutanixIdentifierBootPriorityNetwork is a resource identifier identifying the boot priority as network (PXE) for virtual machines.
+	NutanixIdentifierBootPriorityNetwork string = "network"
+
+	// NutanixIdentifierBusTypeSCSI is a resource identifier identifying the SCSI bus type for virtual machine disks.
+	NutanixIdentifierBusTypeSCSI string = "scsi"
+
+	// NutanixIdentifierBusTypeIDE is a resource identifier identifying the IDE bus type for virtual machine disks and CD-ROMs.
+	NutanixIdentifierBusTypeIDE string = "ide"
+
+	// NutanixIdentifierBusTypeSATA is a resource identifier identifying the SATA bus type for virtual machine disks and CD-ROMs.
+	NutanixIdentifierBusTypeSATA string = "sata"
+
+	// NutanixIdentifierBusTypePCI is a resource identifier identifying the PCI bus type for virtual machine disks.
+	NutanixIdentifierBusTypePCI string = "pci"
+
+	// NutanixIdentifierMediaChangeAfterBoot is a resource identifier identifying the media change stage right after the VM boot.
+	NutanixIdentifierMediaChangeAfterBoot string = "after_boot"
+
+	// NutanixIdentifierMediaChangeBeforeProvision is a resource identifier identifying the media change stage before the provisioners run.
+	NutanixIdentifierMediaChangeBeforeProvision string = "before_provision"
+
+	// NutanixIdentifierMediaChangeAfterProvision is a resource identifier identifying the media change stage after the provisioners run.
+	NutanixIdentifierMediaChangeAfterProvision string = "after_provision"
+
+	// NutanixIdentifierMediaChangeInsert is a resource identifier identifying the media change action inserting an image in a CD-ROM.
+	NutanixIdentifierMediaChangeInsert string = "insert"
+
+	// NutanixIdentifierMediaChangeEject is a resource identifier identifying the media change action ejecting the image from a CD-ROM.
+	NutanixIdentifierMediaChangeEject string = "eject"
+
+	// NutanixIdentifierOnConflictFail is a resource identifier identifying the failure of the build when a template or OVA with the same name exists.
+	NutanixIdentifierOnConflictFail string = "fail"
+
+	// NutanixIdentifierOnConflictReplace is a resource identifier identifying the replacement of an existing template or OVA with the same name.
+	NutanixIdentifierOnConflictReplace string = "replace"
+
+	// NutanixIdentifierOnConflictSkip is a resource identifier identifying the skip of the template or OVA creation when one with the same name exists.
+	NutanixIdentifierOnConflictSkip string = "skip"
+
+	// NutanixIdentifierPlacementTypeSoft is a resource identifier identifying the soft placement of images on clusters.
+	NutanixIdentifierPlacementTypeSoft string = "soft"
+
+	// NutanixIdentifierPlacementTypeHard is a resource identifier identifying the hard placement of images on clusters.
+	NutanixIdentifierPlacementTypeHard string = "hard"
+
+	// NutanixIdentifierExportFormatRaw is a resource identifier identifying the raw format for exported images.
+	NutanixIdentifierExportFormatRaw string = "raw"
+
+	// NutanixIdentifierExportFormatQCOW2 is a resource identifier identifying the qcow2 format for exported images.
+	NutanixIdentifierExportFormatQCOW2 string = "qcow2"
+
+	// NutanixIdentifierExportFormatVMDK is a resource identifier identifying the vmdk format for exported images.
+	NutanixIdentifierExportFormatVMDK string = "vmdk"
+
+	// NutanixIdentifierExportFormatVHDX is a resource identifier identifying the vhdx format for exported images.
+	NutanixIdentifierExportFormatVHDX string = "vhdx"
+
+	// NutanixIdentifierExportCompressionGzip is a resource identifier identifying the gzip compression of exported images.
+	NutanixIdentifierExportCompressionGzip string = "gzip"
+
+	// NutanixIdentifierExportCompressionZstd is a resource identifier identifying the zstd compression of exported images.
+	NutanixIdentifierExportCompressionZstd string = "zstd"
+
+	// NutanixIdentifierExportCompressionXz is a resource identifier identifying the xz compression of exported images.
+	NutanixIdentifierExportCompressionXz string = "xz"
+
 	// NutanixIdentifierChecksunTypeSHA256 is a resource identifier identifying the SHA-256 checksum type for virtual machines.
 	NutanixIdentifierChecksunTypeSHA256 string = "sha256"
 
 	// NutanixIdentifierChecksunTypeSHA1 is a resource identifier identifying the SHA-1 checksum type for virtual machines.
 	NutanixIdentifierChecksunTypeSHA1 string = "sha1"
+
+	// NutanixIdentifierChecksunTypeSHA512 is a resource identifier identifying the SHA-512 checksum type for virtual machines.
+	NutanixIdentifierChecksunTypeSHA512 string = "sha512"
+
+	// NutanixIdentifierChecksunTypeMD5 is a resource identifier identifying the MD5 checksum type for virtual machines.
+	NutanixIdentifierChecksunTypeMD5 string = "md5"
 )
 
 type Config struct {
@@ -52,24 +126,47 @@ type Config struct {
 	shutdowncommand.ShutdownConfig `mapstructure:",squash"`
 	ClusterConfig                  `mapstructure:",squash"`
 	VmConfig                       `mapstructure:",squash"`
-	OvaConfig                      OvaConfig      `mapstructure:"ova" required:"false"`
-	TemplateConfig                 TemplateConfig `mapstructure:"template" required:"false"`
-	ForceDeregister                bool           `mapstructure:"force_deregister" json:"force_deregister" required:"false"`
-	ImageDescription               string         `mapstructure:"image_description" json:"image_description" required:"false"`
-	ImageCategories                []Category     `mapstructure:"image_categories" required:"false"`
-	AllowDuplicateImages           bool           `mapstructure:"allow_duplicate_images" json:"allow_duplicate_images" required:"false"`
-	ImageSkip                      bool           `mapstructure:"image_skip" json:"image_skip" required:"false"`
-	ImageDelete                    bool           `mapstructure:"image_delete" json:"image_delete" required:"false"`
-	ImageExport                    bool           `mapstructure:"image_export" json:"image_export" required:"false"`
-	FailIfImageExists              bool           `mapstructure:"fail_if_image_exists" required:"false"`
-	VmForceDelete                  bool           `mapstructure:"vm_force_delete" json:"vm_force_delete" required:"false"`
-	VmRetain                       bool           `mapstructure:"vm_retain" json:"vm_retain" required:"false"`
-	DisableStopInstance            bool           `mapstructure:"disable_stop_instance" required:"false"`
-	SkipVMCreateTaskCheck          bool           `mapstructure:"skip_vm_create_task_check" required:"false"`
+	OvaConfig                      OvaConfig            `mapstructure:"ova" required:"false"`
+	TemplateConfig                 TemplateConfig       `mapstructure:"template" required:"false"`
+	ForceDeregister                bool                 `mapstructure:"force_deregister" json:"force_deregister" required:"false"`
+	ImageDescription               string               `mapstructure:"image_description" json:"image_description" required:"false"`
+	ImageCategories                []Category           `mapstructure:"image_categories" required:"false"`
+	ImageClusterNames              []string             `mapstructure:"image_cluster_names" json:"image_cluster_names" required:"false"`
+	ImagePlacementPolicy           ImagePlacementPolicy `mapstructure:"image_placement_policy" required:"false"`
+	AllowDuplicateImages           bool                 `mapstructure:"allow_duplicate_images" json:"allow_duplicate_images" required:"false"`
+	UploadRetries                  int                  `mapstructure:"upload_retries" json:"upload_retries" required:"false"`
+	SourceImageDownloadTimeout     time.Duration        `mapstructure:"source_image_download_timeout" json:"source_image_download_timeout" required:"false"`
+	UploadParallelism              int                  `mapstructure:"upload_parallelism" json:"upload_parallelism" required:"false"`
+	ImageSkip                      bool                 `mapstructure:"image_skip" json:"image_skip" required:"false"`
+	ImageDelete                    bool                 `mapstructure:"image_delete" json:"image_delete" required:"false"`
+	ImageExport                    bool                 `mapstructure:"image_export" json:"image_export" required:"false"`
+	ExportFormat                   string               `mapstructure:"export_format" json:"export_format" required:"false"`
+	ExportCompression              string               `mapstructure:"export_compression" json:"export_compression" required:"false"`
+	ExportRetries                  int                  `mapstructure:"export_retries" json:"export_retries" required:"false"`
+	ExportParallelism              int                  `mapstructure:"export_parallelism" json:"export_parallelism" required:"false"`
+	ExportDestination              ExportDestination    `mapstructure:"export_destination" required:"false"`
+	OutputDirectory                string               `mapstructure:"output_directory" json:"output_directory" required:"false"`
+	ForceOutput                    bool                 `mapstructure:"force_output" json:"force_output" required:"false"`
+	FailIfImageExists              bool                 `mapstructure:"fail_if_image_exists" required:"false"`
+	VmForceDelete                  bool                 `mapstructure:"vm_force_delete" json:"vm_force_delete" required:"false"`
+	VmRetain                       bool                 `mapstructure:"vm_retain" json:"vm_retain" required:"false"`
+	DisableStopInstance            bool                 `mapstructure:"disable_stop_instance" required:"false"`
+	SkipVMCreateTaskCheck          bool                 `mapstructure:"skip_vm_create_task_check" required:"false"`
 
 	ctx interpolate.Context
 }
 
+type ExportDestination struct {
+	URL               string `mapstructure:"url" json:"url" required:"false"`
+	Endpoint          string `mapstructure:"endpoint" json:"endpoint" required:"false"`
+	Region            string `mapstructure:"region" json:"region" required:"false"`
+	AccessKey         string `mapstructure:"access_key" json:"access_key" required:"false"`
+	SecretKey         string `mapstructure:"secret_key" json:"secret_key" required:"false"`
+	SessionToken      string `mapstructure:"session_token" json:"session_token" required:"false"`
+	ChecksumAlgorithm string `mapstructure:"checksum_algorithm" json:"checksum_algorithm" required:"false"`
+	PartSize          int    `mapstructure:"part_size" json:"part_size" required:"false"`
+}
+
 type GPU struct {
 	Name string `mapstructure:"name" json:"name" required:"false"`
 }
@@ -93,17 +190,67 @@ type ClusterConfig struct {
 }
 
 type VmDisk struct {
-	ImageType               string `mapstructure:"image_type" json:"image_type" required:"false"`
-	SourceImageName         string `mapstructure:"source_image_name" json:"source_image_name" required:"false"`
-	SourceImageUUID         string `mapstructure:"source_image_uuid" json:"source_image_uuid" required:"false"`
-	SourceImageURI          string `mapstructure:"source_image_uri" json:"source_image_uri" required:"false"`
-	SourceImagePath         string `mapstructure:"source_image_path" json:"source_image_path" required:"false"`
-	SourceImageChecksum     string `mapstructure:"source_image_checksum" json:"source_image_checksum" required:"false"`
-	SourceImageChecksumType string `mapstructure:"source_image_checksum_type" json:"source_image_checksum_type" required:"false"`
-	SourceImageDelete       bool   `mapstructure:"source_image_delete" json:"source_image_delete" required:"false"`
-	SourceImageForce        bool   `mapstructure:"source_image_force" json:"source_image_force" required:"false"`
-	DiskSizeGB              int64  `mapstructure:"disk_size_gb" json:"disk_size_gb" required:"false"`
-	StorageContainerUUID    string `mapstructure:"storage_container_uuid" json:"storage_container_uuid" required:"false"`
+	ImageType               string      `mapstructure:"image_type" json:"image_type" required:"false"`
+	SourceImageName         string      `mapstructure:"source_image_name" json:"source_image_name" required:"false"`
+	SourceImageUUID         string      `mapstructure:"source_image_uuid" json:"source_image_uuid" required:"false"`
+	SourceImageURI          string      `mapstructure:"source_image_uri" json:"source_image_uri" required:"false"`
+	SourceImagePath         string      `mapstructure:"source_image_path" json:"source_image_path" required:"false"`
+	SourceImageFilter       ImageFilter `mapstructure:"source_image_filter" json:"source_image_filter" required:"false"`
+	SourceImageChecksum     string      `mapstructure:"source_image_checksum" json:"source_image_checksum" required:"false"`
+	SourceImageChecksumType string      `mapstructure:"source_image_checksum_type" json:"source_image_checksum_type" required:"false"`
+	SourceImageDelete       bool        `mapstructure:"source_image_delete" json:"source_image_delete" required:"false"`
+	SourceImageForce        bool        `mapstructure:"source_image_force" json:"source_image_force" required:"false"`
+	DiskSizeGB              int64       `mapstructure:"disk_size_gb" json:"disk_size_gb" required:"false"`
+	StorageContainerUUID    string      `mapstructure:"storage_container_uuid" json:"storage_container_uuid" required:"false"`
+	StorageContainerName    string      `mapstructure:"storage_container_name" json:"storage_container_name" required:"false"`
+	BusType                 string      `mapstructure:"bus_type" json:"bus_type" required:"false"`
+	Index                   *int        `mapstructure:"index" json:"index" required:"false"`
+	SaveImage               *bool       `mapstructure:"save_image" json:"save_image" required:"false"`
+	OutputImageName         string      `mapstructure:"output_image_name" json:"output_image_name" required:"false"`
+	OutputImageDescription  string      `mapstructure:"output_image_description" json:"output_image_description" required:"false"`
+	OutputImageCategories   []Category  `mapstructure:"output_image_categories" json:"output_image_categories" required:"false"`
+}
+
+// ImagePlacementPolicy places the output images, selected by their image_categories, on the
+// clusters with the cluster categories.
+type ImagePlacementPolicy struct {
+	Name              string     `mapstructure:"name" json:"name" required:"false"`
+	ClusterCategories []Category `mapstructure:"cluster_categories" json:"cluster_categories" required:"false"`
+	PlacementType     string     `mapstructure:"placement_type" json:"placement_type" required:"false"`
+}
+
+// ClusterSelector lists the candidate clusters of the build, by name or by category, the one
+// with the most free resources is used.
+type ClusterSelector struct {
+	Names      []string   `mapstructure:"names" json:"names" required:"false"`
+	Categories []Category `mapstructure:"categories" json:"categories" required:"false"`
+}
+
+// empty reports whether no candidate cluster is set.
+func (s *ClusterSelector) empty() bool {
+	return len(s.Names) == 0 && len(s.Categories) == 0
+}
+
+// ImageFilter selects the source image of a disk among the images of Prism Central.
+type ImageFilter struct {
+	NameRegex    string     `mapstructure:"name_regex" json:"name_regex" required:"false"`
+	Categories   []Category `mapstructure:"categories" json:"categories" required:"false"`
+	ImageType    string     `mapstructure:"image_type" json:"image_type" required:"false"`
+	CreatedAfter string     `mapstructure:"created_after" json:"created_after" required:"false"`
+	MostRecent   bool       `mapstructure:"most_recent" json:"most_recent" required:"false"`
+}
+
+// empty reports whether the filter has no criteria.
+func (f *ImageFilter) empty() bool {
+	return f.NameRegex == "" && len(f.Categories) == 0 && f.ImageType == "" && f.CreatedAfter == "" && !f.MostRecent
+}
+
+// saveImage reports whether the disk is saved as an output image.
+func (d *VmDisk) saveImage() bool {
+	if d.ImageType == "ISO_IMAGE" {
+		return false
+	}
+	return d.SaveImage == nil || *d.SaveImage
 }
 
 type VmNIC struct {
@@ -114,43 +261,154 @@ type VmNIC struct {
 	SkipIPAssignment bool   `mapstructure:"skip_ip_assignment" json:"skip_ip_assignment" required:"false"`
 }
 type VmConfig struct {
-	VMName                 string     `mapstructure:"vm_name" json:"vm_name" required:"false"`
-	OSType                 string     `mapstructure:"os_type" json:"os_type" required:"true"`
-	BootType               string     `mapstructure:"boot_type" json:"boot_type" required:"false"`
-	VTPM                   VTPM       `mapstructure:"vtpm" json:"vtpm" required:"false"`
-	HardwareVirtualization bool       `mapstructure:"hardware_virtualization" json:"hardware_virtualization" required:"false"`
-	BootPriority           string     `mapstructure:"boot_priority" json:"boot_priority" required:"false"`
-	VmDisks                []VmDisk   `mapstructure:"vm_disks"`
-	VmNICs                 []VmNIC    `mapstructure:"vm_nics"`
-	ImageName              string     `mapstructure:"image_name" json:"image_name" required:"false"`
-	ClusterUUID            string     `mapstructure:"cluster_uuid" json:"cluster_uuid" required:"false"`
-	ClusterName            string     `mapstructure:"cluster_name" json:"cluster_name" required:"false"`
-	CPU                    int64      `mapstructure:"cpu" json:"cpu" required:"false"`
-	Core                   int64      `mapstructure:"core" json:"core" required:"false"`
-	MemoryMB               int64      `mapstructure:"memory_mb" json:"memory_mb" required:"false"`
-	UserData               string     `mapstructure:"user_data" json:"user_data" required:"false"`
-	VMCategories           []Category `mapstructure:"vm_categories" required:"false"`
-	Project                string     `mapstructure:"project" required:"false"`
-	GPU                    []GPU      `mapstructure:"gpu" required:"false"`
-	SerialPort             bool       `mapstructure:"serialport" json:"serialport" required:"false"`
-	Clean                  VmClean    `mapstructure:"vm_clean" json:"vm_clean" required:"false"`
+	VMName                 string          `mapstructure:"vm_name" json:"vm_name" required:"false"`
+	OSType                 string          `mapstructure:"os_type" json:"os_type" required:"true"`
+	BootType               string          `mapstructure:"boot_type" json:"boot_type" required:"false"`
+	VTPM                   VTPM            `mapstructure:"vtpm" json:"vtpm" required:"false"`
+	HardwareVirtualization bool            `mapstructure:"hardware_virtualization" json:"hardware_virtualization" required:"false"`
+	BootPriority           string          `mapstructure:"boot_priority" json:"boot_priority" required:"false"`
+	BootOrder              []string        `mapstructure:"boot_order" json:"boot_order" required:"false"`
+	VmDisks                []VmDisk        `mapstructure:"vm_disks"`
+	VmNICs                 []VmNIC         `mapstructure:"vm_nics"`
+	ImageName              string          `mapstructure:"image_name" json:"image_name" required:"false"`
+	ClusterUUID            string          `mapstructure:"cluster_uuid" json:"cluster_uuid" required:"false"`
+	ClusterName            string          `mapstructure:"cluster_name" json:"cluster_name" required:"false"`
+	ClusterSelector        ClusterSelector `mapstructure:"cluster_selector" json:"cluster_selector" required:"false"`
+	CPU                    int64           `mapstructure:"cpu" json:"cpu" required:"false"`
+	Core                   int64           `mapstructure:"core" json:"core" required:"false"`
+	MemoryMB               int64           `mapstructure:"memory_mb" json:"memory_mb" required:"false"`
+	UserData               string          `mapstructure:"user_data" json:"user_data" required:"false"`
+	VMCategories           []Category      `mapstructure:"vm_categories" required:"false"`
+	Project                string          `mapstructure:"project" required:"false"`
+	GPU                    []GPU           `mapstructure:"gpu" required:"false"`
+	SerialPort             bool            `mapstructure:"serialport" json:"serialport" required:"false"`
+	Clean                  VmClean         `mapstructure:"vm_clean" json:"vm_clean" required:"false"`
+	BootSwitch             BootSwitch      `mapstructure:"boot_switch" json:"boot_switch" required:"false"`
+	MediaChanges           []MediaChange   `mapstructure:"media_change" json:"media_change" required:"false"`
+	SourceTemplateName     string          `mapstructure:"source_template_name" json:"source_template_name" required:"false"`
+	SourceTemplateUUID     string          `mapstructure:"source_template_uuid" json:"source_template_uuid" required:"false"`
+	SourceTemplateVersion  string          `mapstructure:"source_template_version" json:"source_template_version" required:"false"`
+	SourceVMName           string          `mapstructure:"source_vm_name" json:"source_vm_name" required:"false"`
+	SourceVMUUID           string          `mapstructure:"source_vm_uuid" json:"source_vm_uuid" required:"false"`
+	SourceRecoveryPoint    string          `mapstructure:"source_recovery_point" json:"source_recovery_point" required:"false"`
 }
 
 type VmClean struct {
-	Cdrom bool `mapstructure:"cdrom" json:"cdrom" required:"false"`
+	Cdrom              bool `mapstructure:"cdrom" json:"cdrom" required:"false"`
+	Nics               bool `mapstructure:"nics" json:"nics" required:"false"`
+	ResetMac           bool `mapstructure:"reset_mac" json:"reset_mac" required:"false"`
+	SerialPort         bool `mapstructure:"serialport" json:"serialport" required:"false"`
+	GPU                bool `mapstructure:"gpu" json:"gpu" required:"false"`
+	Categories         bool `mapstructure:"categories" json:"categories" required:"false"`
+	GuestCustomization bool `mapstructure:"guest_customization" json:"guest_customization" required:"false"`
+}
+
+// enabled reports whether any VM cleaning option is set.
+func (c *VmClean) enabled() bool {
+	return c.Cdrom || c.Nics || c.ResetMac || c.SerialPort || c.GPU || c.Categories || c.GuestCustomization
+}
+
+type BootSwitch struct {
+	DiskFirst  bool          `mapstructure:"disk_first" json:"disk_first" required:"false"`
+	EjectCdrom bool          `mapstructure:"eject_cdrom" json:"eject_cdrom" required:"false"`
+	CdromIndex *int          `mapstructure:"cdrom_index" json:"cdrom_index" required:"false"`
+	Wait       time.Duration `mapstructure:"wait" json:"wait" required:"false"`
+}
+
+// installCdromIndex returns the device index of the CD-ROM holding the install ISO: the
+// configured cdrom_index, or the index of the first ISO_IMAGE disk.
+func (c *VmConfig) installCdromIndex() (int, bool) {
+	if c.BootSwitch.CdromIndex != nil {
+		return *c.BootSwitch.CdromIndex, true
+	}
+
+	addresses, err := diskAddresses(c.VmDisks)
+	if err != nil {
+		return 0, false
+	}
+	for i, disk := range c.VmDisks {
+		if disk.ImageType == "ISO_IMAGE" {
+			return addresses[i].index, true
+		}
+	}
+	return 0, false
+}
+
+type MediaChange struct {
+	Name            string `mapstructure:"name" json:"name" required:"false"`
+	Stage           string `mapstructure:"stage" json:"stage" required:"false"`
+	Action          string `mapstructure:"action" json:"action" required:"false"`
+	CdromIndex      int    `mapstructure:"cdrom_index" json:"cdrom_index" required:"false"`
+	SourceImageName string `mapstructure:"source_image_name" json:"source_image_name" required:"false"`
+	SourceImageUUID string `mapstructure:"source_image_uuid" json:"source_image_uuid" required:"false"`
+}
+
+// templateSource reports whether the VM is deployed from a template.
+func (c *VmConfig) templateSource() bool {
+	return c.SourceTemplateName != "" || c.SourceTemplateUUID != ""
+}
+
+// vmSource reports whether the VM is cloned from another VM.
+func (c *VmConfig) vmSource() bool {
+	return c.SourceVMName != "" || c.SourceVMUUID != ""
+}
+
+// networkBoot reports whether the VM is configured to boot from the network first.
+func (c *VmConfig) networkBoot() bool {
+	if len(c.BootOrder) > 0 {
+		return c.BootOrder[0] == NutanixIdentifierBootPriorityNetwork
+	}
+	return c.BootPriority == NutanixIdentifierBootPriorityNetwork
 }
 
 type OvaConfig struct {
-	Export bool   `mapstructure:"export" json:"export" required:"false"`
-	Create bool   `mapstructure:"create" json:"create" required:"false"`
-	Format string `mapstructure:"format" json:"format" required:"false"`
-	Name   string `mapstructure:"name" json:"name" required:"false"`
+	Export            bool   `mapstructure:"export" json:"export" required:"false"`
+	Create            bool   `mapstructure:"create" json:"create" required:"false"`
+	Format            string `mapstructure:"format" json:"format" required:"false"`
+	Name              string `mapstructure:"name" json:"name" required:"false"`
+	OnConflict        string `mapstructure:"on_conflict" json:"on_conflict" required:"false"`
+	DeleteAfterExport bool   `mapstructure:"delete_after_export" json:"delete_after_export" required:"false"`
 }
 
 type TemplateConfig struct {
-	Create      bool   `mapstructure:"create" json:"create" required:"false"`
-	Name        string `mapstructure:"name" json:"name" required:"false"`
-	Description string `mapstructure:"description" json:"description" required:"false"`
+	Create             bool                       `mapstructure:"create" json:"create" required:"false"`
+	Name               string                     `mapstructure:"name" json:"name" required:"false"`
+	Description        string                     `mapstructure:"description" json:"description" required:"false"`
+	UpdateExisting     bool                       `mapstructure:"update_existing" json:"update_existing" required:"false"`
+	VersionName        string                     `mapstructure:"version_name" json:"version_name" required:"false"`
+	VersionDescription string                     `mapstructure:"version_description" json:"version_description" required:"false"`
+	Active             *bool                      `mapstructure:"active" json:"active" required:"false"`
+	Retention          int                        `mapstructure:"retention" json:"retention" required:"false"`
+	Categories         []Category                 `mapstructure:"categories" json:"categories" required:"false"`
+	GuestCustomization TemplateGuestCustomization `mapstructure:"guest_customization" json:"guest_customization" required:"false"`
+	Project            string                     `mapstructure:"project" json:"project" required:"false"`
+	Owner              string                     `mapstructure:"owner" json:"owner" required:"false"`
+	OnConflict         string                     `mapstructure:"on_conflict" json:"on_conflict" required:"false"`
+}
+
+type TemplateGuestCustomization struct {
+	UserData    string `mapstructure:"user_data" json:"user_data" required:"false"`
+	Overridable *bool  `mapstructure:"overridable" json:"overridable" required:"false"`
+}
+
+// activeVersion reports whether the template version created by the build is marked active.
+func (c *TemplateConfig) activeVersion() bool {
+	return c.Active == nil || *c.Active
+}
+
+// validOnConflict reports whether onConflict is a supported on_conflict value. Empty keeps the
+// default behavior of creating another entity with the same name.
+func validOnConflict(onConflict string) bool {
+	switch onConflict {
+	case "", NutanixIdentifierOnConflictFail, NutanixIdentifierOnConflictReplace, NutanixIdentifierOnConflictSkip:
+		return true
+	}
+	return false
+}
+
+// overridable reports whether the guest customization of the template can be overridden at deployment.
+func (c *TemplateGuestCustomization) overridable() bool {
+	return c.Overridable == nil || *c.Overridable
 }
 
 func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
@@ -168,6 +426,9 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 		return nil, err
 	}
 
+	// Keep the credentials out of the logs
+	packersdk.LogSecretFilter.Set(c.ClusterConfig.Password, c.ExportDestination.AccessKey, c.ExportDestination.SecretKey, c.ExportDestination.SessionToken)
+
 	// Accumulate any errors and warnings
 	var errs *packersdk.MultiError
 	warnings := make([]string, 0)
@@ -178,20 +439,20 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 		c.Comm.Type = "ssh"
 	}
 
-	// Set Default CPU Configuration
-	if c.CPU == 0 {
+	// Set Default CPU Configuration, VMs deployed from a template or cloned keep the source sizing unless set
+	if c.CPU == 0 && !c.VmConfig.templateSource() && !c.VmConfig.vmSource() {
 		log.Println("No CPU configured, defaulting to '1'")
 		c.CPU = 1
 	}
 
 	// Set Default Core Configuration
-	if c.Core == 0 {
+	if c.Core == 0 && !c.VmConfig.templateSource() && !c.VmConfig.vmSource() {
 		log.Println("No Core configured, defaulting to '1'")
 		c.Core = 1
 	}
 
 	// Set Default Memory Configuration
-	if c.MemoryMB == 0 {
+	if c.MemoryMB == 0 && !c.VmConfig.templateSource() && !c.VmConfig.vmSource() {
 		log.Println("No VM Memory configured, defaulting to '4096'")
 		c.MemoryMB = 4096
 	}
@@ -221,11 +482,52 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 	}
 
 	// Validate Boot Priority
-	if c.BootPriority != NutanixIdentifierBootPriorityDisk && c.BootPriority != NutanixIdentifierBootPriorityCDROM {
+	if c.BootPriority != NutanixIdentifierBootPriorityDisk && c.BootPriority != NutanixIdentifierBootPriorityCDROM && c.BootPriority != NutanixIdentifierBootPriorityNetwork {
 		log.Println("No correct VM Boot Priority configured, defaulting to 'cdrom'")
 		c.BootPriority = string(NutanixIdentifierBootPriorityCDROM)
 	}
 
+	// Validate Boot Order
+	seenBootDevices := make(map[string]bool)
+	for _, device := range c.BootOrder {
+		if device != NutanixIdentifierBootPriorityDisk && device != NutanixIdentifierBootPriorityCDROM && device != NutanixIdentifierBootPriorityNetwork {
+			log.Printf("Boot device %s not supported in boot_order", device)
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("boot_order: device %s not supported (use 'disk', 'cdrom' or 'network')", device))
+			continue
+		}
+		if seenBootDevices[device] {
+			log.Printf("Boot device %s defined more than once in boot_order", device)
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("boot_order: device %s defined more than once", device))
+		}
+		seenBootDevices[device] = true
+	}
+
+	// Validate boot switch wait
+	if c.BootSwitch.Wait < 0 {
+		log.Println("boot_switch.wait must be >= 0")
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("boot_switch.wait must be >= 0"))
+	}
+
+	// The install ISO is ejected once the installer runs from memory, which takes some time
+	// after the boot command
+	if c.BootSwitch.EjectCdrom {
+		if c.BootSwitch.Wait == 0 {
+			log.Println("boot_switch.eject_cdrom without wait")
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("boot_switch.eject_cdrom needs a wait for the installer to load"))
+		}
+
+		if _, ok := c.VmConfig.installCdromIndex(); !ok {
+			log.Println("boot_switch.eject_cdrom without install ISO")
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("boot_switch.eject_cdrom needs an ISO_IMAGE vm_disks or a cdrom_index"))
+		}
+	}
+
+	// vm_clean.nics and vm_clean.reset_mac are mutually exclusive
+	if c.Clean.Nics && c.Clean.ResetMac {
+		log.Println("vm_clean.nics and vm_clean.reset_mac are mutually exclusive, please use only one of them")
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("vm_clean.nics and vm_clean.reset_mac are mutually exclusive, please use only one of them"))
+	}
+
 	// Validate Cluster Endpoint
 	if c.ClusterConfig.Endpoint == "" {
 		log.Println("Nutanix Endpoint missing from configuration")
@@ -245,6 +547,108 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 		c.ImageDelete = true
 	}
 
+	// Set image export format if not provided
+	if c.ExportFormat == "" {
+		c.ExportFormat = NutanixIdentifierExportFormatRaw
+	}
+
+	// Validate image export format and compression
+	switch c.ExportFormat {
+	case NutanixIdentifierExportFormatRaw:
+	case NutanixIdentifierExportFormatQCOW2, NutanixIdentifierExportFormatVMDK, NutanixIdentifierExportFormatVHDX:
+		// Images are downloaded raw and converted with qemu-img, looked up when the export runs
+	default:
+		log.Println("Incorrect export format")
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("export_format should be 'raw', 'qcow2', 'vmdk' or 'vhdx'"))
+	}
+
+	// Set export download retries if not provided
+	if c.ExportRetries == 0 {
+		c.ExportRetries = 3
+	}
+
+	if c.ExportRetries < 0 {
+		log.Println("Export retries must be > 0")
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("export_retries must be > 0"))
+	}
+
+	// Set source image download timeout if not provided
+	if c.SourceImageDownloadTimeout == 0 {
+		c.SourceImageDownloadTimeout = time.Hour
+	}
+
+	if c.SourceImageDownloadTimeout < 0 {
+		log.Println("Source image download timeout must be > 0")
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("source_image_download_timeout must be > 0"))
+	}
+
+	// Set image upload retries if not provided
+	if c.UploadRetries == 0 {
+		c.UploadRetries = 3
+	}
+
+	if c.UploadRetries < 0 {
+		log.Println("Upload retries must be > 0")
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("upload_retries must be > 0"))
+	}
+
+	// Set image upload parallelism if not provided
+	if c.UploadParallelism == 0 {
+		c.UploadParallelism = 1
+	}
+
+	if c.UploadParallelism < 0 {
+		log.Println("Upload parallelism must be > 0")
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("upload_parallelism must be > 0"))
+	}
+
+	// Set export parallelism if not provided
+	if c.ExportParallelism == 0 {
+		c.ExportParallelism = 1
+	}
+
+	if c.ExportParallelism < 0 {
+		log.Println("Export parallelism must be > 0")
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("export_parallelism must be > 0"))
+	}
+
+	// Validate export destination
+	if c.ExportDestination.URL != "" {
+		if _, _, err := parseS3URL(c.ExportDestination.URL); err != nil {
+			log.Println("Incorrect export destination url")
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("export_destination.url: %s", err))
+		}
+
+		if (c.ExportDestination.AccessKey == "") != (c.ExportDestination.SecretKey == "") {
+			log.Println("Export destination access_key and secret_key must be set together")
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("export_destination.access_key and export_destination.secret_key must be set together"))
+		}
+
+		switch c.ExportDestination.ChecksumAlgorithm {
+		case "", "CRC32", "CRC32C", "SHA1", "SHA256":
+		default:
+			log.Println("Incorrect export destination checksum algorithm")
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("export_destination.checksum_algorithm should be 'CRC32', 'CRC32C', 'SHA1' or 'SHA256'"))
+		}
+
+		// Set multipart upload part size if not provided, S3 parts are 5 MiB at least
+		if c.ExportDestination.PartSize == 0 {
+			c.ExportDestination.PartSize = 64
+		}
+
+		if c.ExportDestination.PartSize < 5 {
+			log.Println("Export destination part size must be >= 5")
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("export_destination.part_size must be >= 5"))
+		}
+	}
+
+	switch c.ExportCompression {
+	case "", NutanixIdentifierExportCompressionGzip, NutanixIdentifierExportCompressionZstd, NutanixIdentifierExportCompressionXz:
+	default:
+		log.Println("Incorrect export compression")
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("export_compression should be 'gzip', 'zstd' or 'xz'"))
+	}
+
 	// fail_if_image_exists and force_deregister are mutually exclusive
 	if c.FailIfImageExists && c.ForceDeregister {
 		log.Println("fail_if_image_exists and force_deregister are mutually exclusive, please use only one of them")
@@ -262,25 +666,93 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("ova.format should be 'vmdk' or 'qcow2'"))
 	}
 
-	// Validate Cluster Name
-	if c.VmConfig.ClusterName == "" && c.VmConfig.ClusterUUID == "" {
+	// Validate Cluster Name, an explicit cluster overrides the cluster selector
+	if c.VmConfig.ClusterName == "" && c.VmConfig.ClusterUUID == "" && c.VmConfig.ClusterSelector.empty() {
 		log.Println("Nutanix Cluster Name or UUID missing from configuration")
-		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("missing cluster_name or cluster_uuid"))
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("missing cluster_name, cluster_uuid or cluster_selector"))
+	}
+
+	if selector := c.VmConfig.ClusterSelector; !selector.empty() {
+		if len(selector.Names) > 0 && len(selector.Categories) > 0 {
+			log.Println("Both cluster selector names and categories configured")
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("cluster_selector names and categories are mutually exclusive"))
+		}
+
+		for _, category := range selector.Categories {
+			if category.Key == "" || category.Value == "" {
+				log.Println("Cluster selector category name or value missing")
+				errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("cluster_selector categories entries need both key and value"))
+			}
+		}
+
+		if c.VmConfig.ClusterName != "" || c.VmConfig.ClusterUUID != "" {
+			log.Println("Cluster selector ignored, cluster_name or cluster_uuid configured")
+		} else if c.VmConfig.vmSource() || c.VmConfig.SourceRecoveryPoint != "" {
+			// Cloned and restored VMs stay on the cluster of their source
+			log.Println("Cluster selector configured with a source VM")
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("cluster_selector can't be used with source_vm_name, source_vm_uuid or source_recovery_point"))
+		}
 	}
 
-	// Validate VM disks
-	if len(c.VmConfig.VmDisks) == 0 {
+	// Validate VM disks, VMs deployed from a template or cloned get the disks of their source
+	if c.VmConfig.templateSource() {
+		if c.VmConfig.SourceTemplateName != "" && c.VmConfig.SourceTemplateUUID != "" {
+			log.Println("Both source template name and UUID configured")
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("source_template_name and source_template_uuid are mutually exclusive"))
+		}
+
+		if len(c.VmConfig.VmDisks) > 0 || len(c.CDConfig.CDFiles) > 0 || len(c.CDConfig.CDContent) > 0 {
+			log.Println("Nutanix VM Disks configured with a source template")
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("vm_disks, cd_files and cd_content can not be used with a source template"))
+		}
+
+		if c.VmConfig.vmSource() {
+			log.Println("Both source template and source VM configured")
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("source_template_* and source_vm_* are mutually exclusive"))
+		}
+
+		if c.VmConfig.SourceRecoveryPoint != "" {
+			log.Println("Source recovery point configured with a source template")
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("source_recovery_point can be used only with source_vm_name or source_vm_uuid"))
+		}
+	} else if c.VmConfig.vmSource() {
+		if c.VmConfig.SourceVMName != "" && c.VmConfig.SourceVMUUID != "" {
+			log.Println("Both source VM name and UUID configured")
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("source_vm_name and source_vm_uuid are mutually exclusive"))
+		}
+
+		if len(c.VmConfig.VmDisks) > 0 || len(c.CDConfig.CDFiles) > 0 || len(c.CDConfig.CDContent) > 0 {
+			log.Println("Nutanix VM Disks configured with a source VM")
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("vm_disks, cd_files and cd_content can not be used with a source VM"))
+		}
+
+		if c.VmConfig.SourceTemplateVersion != "" {
+			log.Println("Source template version configured with a source VM")
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("source_template_version can be used only with source_template_name or source_template_uuid"))
+		}
+	} else if c.VmConfig.SourceRecoveryPoint != "" {
+		log.Println("Source recovery point configured without source VM")
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("source_recovery_point can be used only with source_vm_name or source_vm_uuid"))
+	} else if c.VmConfig.SourceTemplateVersion != "" {
+		log.Println("Source template version configured without source template")
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("source_template_version can be used only with source_template_name or source_template_uuid"))
+	} else if len(c.VmConfig.VmDisks) == 0 {
 		log.Println("Nutanix VM Disks missing from configuration")
 		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("missing vm_disks"))
 	}
 
 	if c.Comm.Type != "none" {
 
-		// Validate VM nics
-		if len(c.VmConfig.VmNICs) == 0 {
+		// Validate VM nics, VMs deployed from a template can keep the NICs of the template
+		if len(c.VmConfig.VmNICs) == 0 && !c.VmConfig.templateSource() && !c.VmConfig.vmSource() {
 			log.Println("Nutanix VM Nics missing from configuration")
 			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("missing vm_nics"))
 		}
+	} else if c.VmConfig.networkBoot() && len(c.VmConfig.VmNICs) == 0 {
+
+		// Network boot needs at least one NIC to reach the PXE server
+		log.Println("Nutanix VM Nics missing from configuration, required for network boot")
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("missing vm_nics, required for network boot"))
 	}
 
 	// Validate VM Subnet and static NIC IPv4 options
@@ -333,6 +805,17 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 		c.OvaConfig.Name = c.VmConfig.VMName
 	}
 
+	// Validate how existing OVAs with the same name are handled
+	if !validOnConflict(c.OvaConfig.OnConflict) {
+		log.Println("Incorrect ova on_conflict")
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("ova.on_conflict should be 'fail', 'replace' or 'skip'"))
+	}
+
+	if c.OvaConfig.DeleteAfterExport && !c.OvaConfig.Export {
+		log.Println("OVA delete_after_export needs export")
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("ova.delete_after_export can be used only with ova.export"))
+	}
+
 	if c.VmConfig.ImageName == "" {
 		log.Println("No image_name defined, setting to vm_name")
 
@@ -345,6 +828,46 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 		c.TemplateConfig.Name = c.VmConfig.VMName
 	}
 
+	// Validate template versioning options
+	if c.TemplateConfig.Retention < 0 {
+		log.Println("Template retention must be >= 0")
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("template.retention must be >= 0"))
+	}
+
+	if c.TemplateConfig.Retention > 0 && !c.TemplateConfig.UpdateExisting {
+		log.Println("Template retention can be used only with update_existing")
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("template.retention can be used only with template.update_existing"))
+	}
+
+	// Validate how existing templates with the same name are handled
+	if !validOnConflict(c.TemplateConfig.OnConflict) {
+		log.Println("Incorrect template on_conflict")
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("template.on_conflict should be 'fail', 'replace' or 'skip'"))
+	}
+
+	if c.TemplateConfig.OnConflict != "" && c.TemplateConfig.UpdateExisting {
+		log.Println("Template on_conflict can't be used with update_existing")
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("template.on_conflict can't be used with template.update_existing"))
+	}
+
+	// Validate if both template category key and value are given in same time
+	for _, templateCategory := range c.TemplateConfig.Categories {
+		if templateCategory.Key == "" || templateCategory.Value == "" {
+			log.Println("Nutanix Template Category name or value missing from configuration")
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("template.categories entries need both name and value"))
+		}
+	}
+
+	if c.TemplateConfig.GuestCustomization.UserData != "" && c.OSType != "Linux" && c.OSType != "Windows" {
+		log.Println("Template guest customization needs Linux or Windows os_type")
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("template.guest_customization.user_data needs os_type 'Linux' or 'Windows'"))
+	}
+
+	if c.TemplateConfig.Active != nil && !*c.TemplateConfig.Active && !c.TemplateConfig.UpdateExisting {
+		log.Println("Template active can be disabled only with update_existing")
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("template.active = false can be used only with template.update_existing, the first version of a template is always active"))
+	}
+
 	// Validate if both Image Category key and value are given in same time
 	for _, imageCategory := range c.ImageCategories {
 		if imageCategory.Key != "" && imageCategory.Value == "" {
@@ -371,23 +894,62 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 		}
 	}
 
+	// Validate the image placement policy
+	if policy := c.ImagePlacementPolicy; policy.Name != "" || len(policy.ClusterCategories) > 0 || policy.PlacementType != "" {
+		if policy.Name == "" {
+			log.Println("Image placement policy name missing")
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("image_placement_policy needs a name"))
+		}
+
+		if len(policy.ClusterCategories) == 0 {
+			log.Println("Image placement policy cluster categories missing")
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("image_placement_policy needs cluster_categories"))
+		}
+
+		for _, category := range policy.ClusterCategories {
+			if category.Key == "" || category.Value == "" {
+				log.Println("Image placement policy cluster category name or value missing")
+				errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("image_placement_policy cluster_categories entries need both key and value"))
+			}
+		}
+
+		// The policy selects the output images by their categories
+		if len(c.ImageCategories) == 0 {
+			log.Println("Image placement policy without image categories")
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("image_placement_policy needs image_categories to select the output images"))
+		}
+
+		switch policy.PlacementType {
+		case "":
+			c.ImagePlacementPolicy.PlacementType = NutanixIdentifierPlacementTypeSoft
+		case NutanixIdentifierPlacementTypeSoft, NutanixIdentifierPlacementTypeHard:
+		default:
+			log.Println("Incorrect image placement type")
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("image_placement_policy placement_type should be 'soft' or 'hard'"))
+		}
+	}
+
 	// Validate each disk
+	busErrors := false
+	outputImageNames := make(map[string]bool)
 	for index, disk := range c.VmConfig.VmDisks {
 
-		// Validate checksum only with uri
-		if disk.SourceImageChecksum != "" && disk.SourceImageURI == "" {
-			log.Printf("disk %d: Checksum work only with Source Image URI\n", index)
-			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: source_image_checksum work only with source_image_uri", index))
+		// Validate checksum only with uri or path
+		if disk.SourceImageChecksum != "" && disk.SourceImageURI == "" && disk.SourceImagePath == "" {
+			log.Printf("disk %d: Checksum work only with Source Image URI or path\n", index)
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: source_image_checksum work only with source_image_uri or source_image_path", index))
 		}
 
 		// Validate supported checksum type
-		if disk.SourceImageChecksumType != "" && disk.SourceImageChecksumType != NutanixIdentifierChecksunTypeSHA1 && disk.SourceImageChecksumType != NutanixIdentifierChecksunTypeSHA256 {
+		switch disk.SourceImageChecksumType {
+		case "", NutanixIdentifierChecksunTypeSHA1, NutanixIdentifierChecksunTypeSHA256, NutanixIdentifierChecksunTypeSHA512, NutanixIdentifierChecksunTypeMD5:
+		default:
 			log.Printf("disk %d: Checksum type %s not supported\n", index, disk.SourceImageChecksumType)
 			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: checksum_type %s not supported", index, disk.SourceImageChecksumType))
 		}
 
-		// Validate Checksum type always defined with checksum
-		if disk.SourceImageChecksum != "" && disk.SourceImageChecksumType == "" {
+		// Validate Checksum type always defined with checksum, checksum files give it by the digest length
+		if disk.SourceImageChecksum != "" && disk.SourceImageChecksumType == "" && !strings.HasPrefix(disk.SourceImageChecksum, checksumFilePrefix) {
 			log.Printf("disk %d: Checksum type need to be defined\n", index)
 			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: source_image_checksum_type need to be defined", index))
 		}
@@ -398,10 +960,54 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: no source_image_checksum set despite checksum_type configured", index))
 		}
 
-		// Validate storage container defined only with disk type
-		if (disk.StorageContainerUUID != "") && disk.ImageType != "DISK" {
-			log.Printf("disk %d: Storage container UUID can be set only with DISK image type\n", index)
-			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: storage_container_uuid can be set only with DISK image type", index))
+		// Validate storage container defined only with disk types
+		if (disk.StorageContainerUUID != "" || disk.StorageContainerName != "") && disk.ImageType != "DISK" && disk.ImageType != "DISK_IMAGE" {
+			log.Printf("disk %d: Storage container can be set only with DISK or DISK_IMAGE image type\n", index)
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: storage_container_uuid and storage_container_name can be set only with DISK or DISK_IMAGE image type", index))
+		}
+
+		if disk.StorageContainerUUID != "" && disk.StorageContainerName != "" {
+			log.Printf("disk %d: Storage container UUID and name both set\n", index)
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: storage_container_uuid and storage_container_name can not be used together", index))
+		}
+
+		// Validate the source image filter
+		if filter := disk.SourceImageFilter; !filter.empty() {
+			if disk.SourceImageName != "" || disk.SourceImageUUID != "" || disk.SourceImageURI != "" || disk.SourceImagePath != "" {
+				log.Printf("disk %d: Source image filter can not be used with another source image\n", index)
+				errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: source_image_filter can not be used with source_image_name, source_image_uuid, source_image_uri or source_image_path", index))
+			}
+
+			if disk.ImageType != "DISK_IMAGE" && disk.ImageType != "ISO_IMAGE" {
+				log.Printf("disk %d: Source image filter can be used only with DISK_IMAGE or ISO_IMAGE\n", index)
+				errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: source_image_filter can be used only with DISK_IMAGE or ISO_IMAGE image type", index))
+			}
+
+			if filter.NameRegex != "" {
+				if _, err := regexp.Compile(filter.NameRegex); err != nil {
+					log.Printf("disk %d: Invalid source image filter name regex\n", index)
+					errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: source_image_filter name_regex is invalid: %s", index, err))
+				}
+			}
+
+			for _, category := range filter.Categories {
+				if category.Key == "" || category.Value == "" {
+					log.Printf("disk %d: Source image filter category name or value missing\n", index)
+					errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: source_image_filter categories entries need both key and value", index))
+				}
+			}
+
+			if filter.ImageType != "" && filter.ImageType != "DISK_IMAGE" && filter.ImageType != "ISO_IMAGE" {
+				log.Printf("disk %d: Source image filter type %s not supported\n", index, filter.ImageType)
+				errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: source_image_filter image_type should be 'DISK_IMAGE' or 'ISO_IMAGE'", index))
+			}
+
+			if filter.CreatedAfter != "" {
+				if _, err := time.Parse(time.RFC3339, filter.CreatedAfter); err != nil {
+					log.Printf("disk %d: Invalid source image filter created_after\n", index)
+					errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: source_image_filter created_after should be a RFC 3339 time like 2024-01-02T15:04:05Z", index))
+				}
+			}
 		}
 
 		// Validate if file to upload exists
@@ -418,6 +1024,118 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 			log.Printf("disk %d: Source image delete can be used only with source_image_path or source_image_uri", index)
 			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: source_image_delete can be used only with source_image_path or source_image_uri", index))
 		}
+
+		// Validate output image settings are used only with disks
+		if disk.ImageType == "ISO_IMAGE" && (disk.SaveImage != nil || disk.OutputImageName != "" || disk.OutputImageDescription != "" || len(disk.OutputImageCategories) > 0) {
+			log.Printf("disk %d: Output image settings can not be used with ISO_IMAGE\n", index)
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: save_image and output_image_* can not be used with ISO_IMAGE", index))
+		} else if !disk.saveImage() && (disk.OutputImageName != "" || disk.OutputImageDescription != "" || len(disk.OutputImageCategories) > 0) {
+			log.Printf("disk %d: Output image settings set on a disk not saved\n", index)
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: output_image_* can not be used with save_image = false", index))
+		}
+
+		// Validate output image names are unique
+		if disk.OutputImageName != "" {
+			if outputImageNames[disk.OutputImageName] {
+				log.Printf("disk %d: Output image name %s already used\n", index, disk.OutputImageName)
+				errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: output_image_name %s is already used by another disk", index, disk.OutputImageName))
+			}
+			outputImageNames[disk.OutputImageName] = true
+		}
+
+		// Validate if both output image category key and value are given in same time
+		for _, imageCategory := range disk.OutputImageCategories {
+			if imageCategory.Key == "" || imageCategory.Value == "" {
+				log.Printf("disk %d: Output image category name or value missing\n", index)
+				errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: output_image_categories entries need both name and value", index))
+			}
+		}
+
+		// Validate bus type, CD-ROMs can only use IDE or SATA
+		busType := diskBusType(disk)
+		if disk.ImageType == "ISO_IMAGE" && busType != NutanixIdentifierBusTypeIDE && busType != NutanixIdentifierBusTypeSATA {
+			log.Printf("disk %d: Bus type %s not supported for ISO_IMAGE\n", index, disk.BusType)
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: bus_type %s not supported for ISO_IMAGE (use 'ide' or 'sata')", index, disk.BusType))
+			busErrors = true
+		} else if busType != NutanixIdentifierBusTypeSCSI && busType != NutanixIdentifierBusTypeIDE && busType != NutanixIdentifierBusTypeSATA && busType != NutanixIdentifierBusTypePCI {
+			log.Printf("disk %d: Bus type %s not supported\n", index, disk.BusType)
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: bus_type %s not supported (use 'scsi', 'ide', 'sata' or 'pci')", index, disk.BusType))
+			busErrors = true
+		}
+
+		// Validate device index
+		if disk.Index != nil && *disk.Index < 0 {
+			log.Printf("disk %d: Index must be >= 0\n", index)
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("disk %d: index must be >= 0", index))
+			busErrors = true
+		}
+	}
+
+	// Validate that no two disks or CD-ROMs use the same address. The cd_files CD is
+	// attached at build time on the next free SATA index.
+	allDisks := c.VmConfig.VmDisks
+	if len(c.CDConfig.CDFiles) > 0 || len(c.CDConfig.CDContent) > 0 {
+		allDisks = append(append([]VmDisk{}, allDisks...), VmDisk{ImageType: "ISO_IMAGE"})
+	}
+	var addresses []diskAddress
+	if !busErrors {
+		var err error
+		addresses, err = diskAddresses(allDisks)
+		if err != nil {
+			log.Printf("Conflicting disk addresses: %s", err.Error())
+			errs = packersdk.MultiErrorAppend(errs, err)
+		}
+	}
+
+	// Validate each media change
+	for index := range c.VmConfig.MediaChanges {
+		change := &c.VmConfig.MediaChanges[index]
+
+		if change.Name == "" {
+			change.Name = fmt.Sprintf("media_change %d", index)
+		}
+
+		if change.Stage == "" {
+			change.Stage = NutanixIdentifierMediaChangeBeforeProvision
+		}
+
+		if change.Stage != NutanixIdentifierMediaChangeAfterBoot && change.Stage != NutanixIdentifierMediaChangeBeforeProvision && change.Stage != NutanixIdentifierMediaChangeAfterProvision {
+			log.Printf("%s: stage %s not supported\n", change.Name, change.Stage)
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("%s: stage %s not supported (use 'after_boot', 'before_provision' or 'after_provision')", change.Name, change.Stage))
+		}
+
+		if change.Action != NutanixIdentifierMediaChangeInsert && change.Action != NutanixIdentifierMediaChangeEject {
+			log.Printf("%s: action %s not supported\n", change.Name, change.Action)
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("%s: action must be 'insert' or 'eject'", change.Name))
+		}
+
+		if change.Action == NutanixIdentifierMediaChangeInsert && change.SourceImageName == "" && change.SourceImageUUID == "" {
+			log.Printf("%s: no image to insert\n", change.Name)
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("%s: source_image_name or source_image_uuid is required to insert an image", change.Name))
+		}
+
+		if change.Action == NutanixIdentifierMediaChangeEject && (change.SourceImageName != "" || change.SourceImageUUID != "") {
+			log.Printf("%s: image set with eject action\n", change.Name)
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("%s: source_image_name and source_image_uuid can be used only with insert action", change.Name))
+		}
+
+		// Validate cdrom_index matches exactly one CD-ROM device index. The CD-ROMs of a VM
+		// deployed from a template or cloned from a VM are only known at build time.
+		if addresses != nil && !c.VmConfig.templateSource() && !c.VmConfig.vmSource() {
+			matches := 0
+			for i, disk := range allDisks {
+				if disk.ImageType == "ISO_IMAGE" && addresses[i].index == change.CdromIndex {
+					matches++
+				}
+			}
+			if matches == 0 {
+				log.Printf("%s: cdrom_index %d does not match a CD-ROM of the VM\n", change.Name, change.CdromIndex)
+				errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("%s: cdrom_index %d does not match a CD-ROM of the VM", change.Name, change.CdromIndex))
+			} else if matches > 1 {
+				log.Printf("%s: cdrom_index %d matches more than one CD-ROM of the VM\n", change.Name, change.CdromIndex)
+				errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("%s: cdrom_index %d matches CD-ROMs on more than one bus, use distinct indexes", change.Name, change.CdromIndex))
+			}
+		}
 	}
 
 	if c.Comm.SSHPort == 0 {
@@ -7,6 +7,35 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
+// FlatBootSwitch is an auto-generated flat version of BootSwitch.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatBootSwitch struct {
+	DiskFirst  *bool   `mapstructure:"disk_first" json:"disk_first" required:"false" cty:"disk_first" hcl:"disk_first"`
+	EjectCdrom *bool   `mapstructure:"eject_cdrom" json:"eject_cdrom" required:"false" cty:"eject_cdrom" hcl:"eject_cdrom"`
+	CdromIndex *int    `mapstructure:"cdrom_index" json:"cdrom_index" required:"false" cty:"cdrom_index" hcl:"cdrom_index"`
+	Wait       *string `mapstructure:"wait" json:"wait" required:"false" cty:"wait" hcl:"wait"`
+}
+
+// FlatMapstructure returns a new FlatBootSwitch.
+// FlatBootSwitch is an auto-generated flat version of BootSwitch.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*BootSwitch) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatBootSwitch)
+}
+
+// HCL2Spec returns the hcl spec of a BootSwitch.
+// This spec is used by HCL to read the fields of BootSwitch.
+// The decoded values from this spec will then be applied to a FlatBootSwitch.
+func (*FlatBootSwitch) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"disk_first":  &hcldec.AttrSpec{Name: "disk_first", Type: cty.Bool, Required: false},
+		"eject_cdrom": &hcldec.AttrSpec{Name: "eject_cdrom", Type: cty.Bool, Required: false},
+		"cdrom_index": &hcldec.AttrSpec{Name: "cdrom_index", Type: cty.Number, Required: false},
+		"wait":        &hcldec.AttrSpec{Name: "wait", Type: cty.String, Required: false},
+	}
+	return s
+}
+
 // FlatCategory is an auto-generated flat version of Category.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatCategory struct {
@@ -65,119 +94,166 @@ func (*FlatClusterConfig) HCL2Spec() map[string]hcldec.Spec {
 	return s
 }
 
+// FlatClusterSelector is an auto-generated flat version of ClusterSelector.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatClusterSelector struct {
+	Names      []string       `mapstructure:"names" json:"names" required:"false" cty:"names" hcl:"names"`
+	Categories []FlatCategory `mapstructure:"categories" json:"categories" required:"false" cty:"categories" hcl:"categories"`
+}
+
+// FlatMapstructure returns a new FlatClusterSelector.
+// FlatClusterSelector is an auto-generated flat version of ClusterSelector.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*ClusterSelector) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatClusterSelector)
+}
+
+// HCL2Spec returns the hcl spec of a ClusterSelector.
+// This spec is used by HCL to read the fields of ClusterSelector.
+// The decoded values from this spec will then be applied to a FlatClusterSelector.
+func (*FlatClusterSelector) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"names":      &hcldec.AttrSpec{Name: "names", Type: cty.List(cty.String), Required: false},
+		"categories": &hcldec.BlockListSpec{TypeName: "categories", Nested: hcldec.ObjectSpec((*FlatCategory)(nil).HCL2Spec())},
+	}
+	return s
+}
+
 // FlatConfig is an auto-generated flat version of Config.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatConfig struct {
-	PackerBuildName           *string             `mapstructure:"packer_build_name" cty:"packer_build_name" hcl:"packer_build_name"`
-	PackerBuilderType         *string             `mapstructure:"packer_builder_type" cty:"packer_builder_type" hcl:"packer_builder_type"`
-	PackerCoreVersion         *string             `mapstructure:"packer_core_version" cty:"packer_core_version" hcl:"packer_core_version"`
-	PackerDebug               *bool               `mapstructure:"packer_debug" cty:"packer_debug" hcl:"packer_debug"`
-	PackerForce               *bool               `mapstructure:"packer_force" cty:"packer_force" hcl:"packer_force"`
-	PackerOnError             *string             `mapstructure:"packer_on_error" cty:"packer_on_error" hcl:"packer_on_error"`
-	PackerUserVars            map[string]string   `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
-	PackerSensitiveVars       []string            `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
-	WaitTimeout               *string             `mapstructure:"ip_wait_timeout" cty:"ip_wait_timeout" hcl:"ip_wait_timeout"`
-	SettleTimeout             *string             `mapstructure:"ip_settle_timeout" cty:"ip_settle_timeout" hcl:"ip_settle_timeout"`
-	WaitAddress               *string             `mapstructure:"ip_wait_address" cty:"ip_wait_address" hcl:"ip_wait_address"`
-	Type                      *string             `mapstructure:"communicator" cty:"communicator" hcl:"communicator"`
-	PauseBeforeConnect        *string             `mapstructure:"pause_before_connecting" cty:"pause_before_connecting" hcl:"pause_before_connecting"`
-	SSHHost                   *string             `mapstructure:"ssh_host" cty:"ssh_host" hcl:"ssh_host"`
-	SSHPort                   *int                `mapstructure:"ssh_port" cty:"ssh_port" hcl:"ssh_port"`
-	SSHUsername               *string             `mapstructure:"ssh_username" cty:"ssh_username" hcl:"ssh_username"`
-	SSHPassword               *string             `mapstructure:"ssh_password" cty:"ssh_password" hcl:"ssh_password"`
-	SSHKeyPairName            *string             `mapstructure:"ssh_keypair_name" undocumented:"true" cty:"ssh_keypair_name" hcl:"ssh_keypair_name"`
-	SSHTemporaryKeyPairName   *string             `mapstructure:"temporary_key_pair_name" undocumented:"true" cty:"temporary_key_pair_name" hcl:"temporary_key_pair_name"`
-	SSHTemporaryKeyPairType   *string             `mapstructure:"temporary_key_pair_type" cty:"temporary_key_pair_type" hcl:"temporary_key_pair_type"`
-	SSHTemporaryKeyPairBits   *int                `mapstructure:"temporary_key_pair_bits" cty:"temporary_key_pair_bits" hcl:"temporary_key_pair_bits"`
-	SSHCiphers                []string            `mapstructure:"ssh_ciphers" cty:"ssh_ciphers" hcl:"ssh_ciphers"`
-	SSHClearAuthorizedKeys    *bool               `mapstructure:"ssh_clear_authorized_keys" cty:"ssh_clear_authorized_keys" hcl:"ssh_clear_authorized_keys"`
-	SSHKEXAlgos               []string            `mapstructure:"ssh_key_exchange_algorithms" cty:"ssh_key_exchange_algorithms" hcl:"ssh_key_exchange_algorithms"`
-	SSHPrivateKeyFile         *string             `mapstructure:"ssh_private_key_file" undocumented:"true" cty:"ssh_private_key_file" hcl:"ssh_private_key_file"`
-	SSHCertificateFile        *string             `mapstructure:"ssh_certificate_file" cty:"ssh_certificate_file" hcl:"ssh_certificate_file"`
-	SSHPty                    *bool               `mapstructure:"ssh_pty" cty:"ssh_pty" hcl:"ssh_pty"`
-	SSHTimeout                *string             `mapstructure:"ssh_timeout" cty:"ssh_timeout" hcl:"ssh_timeout"`
-	SSHWaitTimeout            *string             `mapstructure:"ssh_wait_timeout" undocumented:"true" cty:"ssh_wait_timeout" hcl:"ssh_wait_timeout"`
-	SSHAgentAuth              *bool               `mapstructure:"ssh_agent_auth" undocumented:"true" cty:"ssh_agent_auth" hcl:"ssh_agent_auth"`
-	SSHDisableAgentForwarding *bool               `mapstructure:"ssh_disable_agent_forwarding" cty:"ssh_disable_agent_forwarding" hcl:"ssh_disable_agent_forwarding"`
-	SSHHandshakeAttempts      *int                `mapstructure:"ssh_handshake_attempts" cty:"ssh_handshake_attempts" hcl:"ssh_handshake_attempts"`
-	SSHBastionHost            *string             `mapstructure:"ssh_bastion_host" cty:"ssh_bastion_host" hcl:"ssh_bastion_host"`
-	SSHBastionPort            *int                `mapstructure:"ssh_bastion_port" cty:"ssh_bastion_port" hcl:"ssh_bastion_port"`
-	SSHBastionAgentAuth       *bool               `mapstructure:"ssh_bastion_agent_auth" cty:"ssh_bastion_agent_auth" hcl:"ssh_bastion_agent_auth"`
-	SSHBastionUsername        *string             `mapstructure:"ssh_bastion_username" cty:"ssh_bastion_username" hcl:"ssh_bastion_username"`
-	SSHBastionPassword        *string             `mapstructure:"ssh_bastion_password" cty:"ssh_bastion_password" hcl:"ssh_bastion_password"`
-	SSHBastionInteractive     *bool               `mapstructure:"ssh_bastion_interactive" cty:"ssh_bastion_interactive" hcl:"ssh_bastion_interactive"`
-	SSHBastionPrivateKeyFile  *string             `mapstructure:"ssh_bastion_private_key_file" cty:"ssh_bastion_private_key_file" hcl:"ssh_bastion_private_key_file"`
-	SSHBastionCertificateFile *string             `mapstructure:"ssh_bastion_certificate_file" cty:"ssh_bastion_certificate_file" hcl:"ssh_bastion_certificate_file"`
-	SSHFileTransferMethod     *string             `mapstructure:"ssh_file_transfer_method" cty:"ssh_file_transfer_method" hcl:"ssh_file_transfer_method"`
-	SSHProxyHost              *string             `mapstructure:"ssh_proxy_host" cty:"ssh_proxy_host" hcl:"ssh_proxy_host"`
-	SSHProxyPort              *int                `mapstructure:"ssh_proxy_port" cty:"ssh_proxy_port" hcl:"ssh_proxy_port"`
-	SSHProxyUsername          *string             `mapstructure:"ssh_proxy_username" cty:"ssh_proxy_username" hcl:"ssh_proxy_username"`
-	SSHProxyPassword          *string             `mapstructure:"ssh_proxy_password" cty:"ssh_proxy_password" hcl:"ssh_proxy_password"`
-	SSHKeepAliveInterval      *string             `mapstructure:"ssh_keep_alive_interval" cty:"ssh_keep_alive_interval" hcl:"ssh_keep_alive_interval"`
-	SSHReadWriteTimeout       *string             `mapstructure:"ssh_read_write_timeout" cty:"ssh_read_write_timeout" hcl:"ssh_read_write_timeout"`
-	SSHRemoteTunnels          []string            `mapstructure:"ssh_remote_tunnels" cty:"ssh_remote_tunnels" hcl:"ssh_remote_tunnels"`
-	SSHLocalTunnels           []string            `mapstructure:"ssh_local_tunnels" cty:"ssh_local_tunnels" hcl:"ssh_local_tunnels"`
-	SSHPublicKey              []byte              `mapstructure:"ssh_public_key" undocumented:"true" cty:"ssh_public_key" hcl:"ssh_public_key"`
-	SSHPrivateKey             []byte              `mapstructure:"ssh_private_key" undocumented:"true" cty:"ssh_private_key" hcl:"ssh_private_key"`
-	WinRMUser                 *string             `mapstructure:"winrm_username" cty:"winrm_username" hcl:"winrm_username"`
-	WinRMPassword             *string             `mapstructure:"winrm_password" cty:"winrm_password" hcl:"winrm_password"`
-	WinRMHost                 *string             `mapstructure:"winrm_host" cty:"winrm_host" hcl:"winrm_host"`
-	WinRMNoProxy              *bool               `mapstructure:"winrm_no_proxy" cty:"winrm_no_proxy" hcl:"winrm_no_proxy"`
-	WinRMPort                 *int                `mapstructure:"winrm_port" cty:"winrm_port" hcl:"winrm_port"`
-	WinRMTimeout              *string             `mapstructure:"winrm_timeout" cty:"winrm_timeout" hcl:"winrm_timeout"`
-	WinRMUseSSL               *bool               `mapstructure:"winrm_use_ssl" cty:"winrm_use_ssl" hcl:"winrm_use_ssl"`
-	WinRMInsecure             *bool               `mapstructure:"winrm_insecure" cty:"winrm_insecure" hcl:"winrm_insecure"`
-	WinRMUseNTLM              *bool               `mapstructure:"winrm_use_ntlm" cty:"winrm_use_ntlm" hcl:"winrm_use_ntlm"`
-	BootGroupInterval         *string             `mapstructure:"boot_keygroup_interval" cty:"boot_keygroup_interval" hcl:"boot_keygroup_interval"`
-	BootWait                  *string             `mapstructure:"boot_wait" cty:"boot_wait" hcl:"boot_wait"`
-	BootCommand               []string            `mapstructure:"boot_command" cty:"boot_command" hcl:"boot_command"`
-	DisableVNC                *bool               `mapstructure:"disable_vnc" cty:"disable_vnc" hcl:"disable_vnc"`
-	BootKeyInterval           *string             `mapstructure:"boot_key_interval" cty:"boot_key_interval" hcl:"boot_key_interval"`
-	CDFiles                   []string            `mapstructure:"cd_files" cty:"cd_files" hcl:"cd_files"`
-	CDContent                 map[string]string   `mapstructure:"cd_content" cty:"cd_content" hcl:"cd_content"`
-	CDLabel                   *string             `mapstructure:"cd_label" cty:"cd_label" hcl:"cd_label"`
-	ShutdownCommand           *string             `mapstructure:"shutdown_command" required:"false" cty:"shutdown_command" hcl:"shutdown_command"`
-	ShutdownTimeout           *string             `mapstructure:"shutdown_timeout" required:"false" cty:"shutdown_timeout" hcl:"shutdown_timeout"`
-	Username                  *string             `mapstructure:"nutanix_username" required:"false" cty:"nutanix_username" hcl:"nutanix_username"`
-	Password                  *string             `mapstructure:"nutanix_password" required:"false" cty:"nutanix_password" hcl:"nutanix_password"`
-	Insecure                  *bool               `mapstructure:"nutanix_insecure" required:"false" cty:"nutanix_insecure" hcl:"nutanix_insecure"`
-	Endpoint                  *string             `mapstructure:"nutanix_endpoint" required:"true" cty:"nutanix_endpoint" hcl:"nutanix_endpoint"`
-	Port                      *int32              `mapstructure:"nutanix_port" required:"false" cty:"nutanix_port" hcl:"nutanix_port"`
-	TransferTimeout           *int                `mapstructure:"nutanix_transfer_timeout" required:"false" cty:"nutanix_transfer_timeout" hcl:"nutanix_transfer_timeout"`
-	VMName                    *string             `mapstructure:"vm_name" json:"vm_name" required:"false" cty:"vm_name" hcl:"vm_name"`
-	OSType                    *string             `mapstructure:"os_type" json:"os_type" required:"true" cty:"os_type" hcl:"os_type"`
-	BootType                  *string             `mapstructure:"boot_type" json:"boot_type" required:"false" cty:"boot_type" hcl:"boot_type"`
-	VTPM                      *FlatVTPM           `mapstructure:"vtpm" json:"vtpm" required:"false" cty:"vtpm" hcl:"vtpm"`
-	HardwareVirtualization    *bool               `mapstructure:"hardware_virtualization" json:"hardware_virtualization" required:"false" cty:"hardware_virtualization" hcl:"hardware_virtualization"`
-	BootPriority              *string             `mapstructure:"boot_priority" json:"boot_priority" required:"false" cty:"boot_priority" hcl:"boot_priority"`
-	VmDisks                   []FlatVmDisk        `mapstructure:"vm_disks" cty:"vm_disks" hcl:"vm_disks"`
-	VmNICs                    []FlatVmNIC         `mapstructure:"vm_nics" cty:"vm_nics" hcl:"vm_nics"`
-	ImageName                 *string             `mapstructure:"image_name" json:"image_name" required:"false" cty:"image_name" hcl:"image_name"`
-	ClusterUUID               *string             `mapstructure:"cluster_uuid" json:"cluster_uuid" required:"false" cty:"cluster_uuid" hcl:"cluster_uuid"`
-	ClusterName               *string             `mapstructure:"cluster_name" json:"cluster_name" required:"false" cty:"cluster_name" hcl:"cluster_name"`
-	CPU                       *int64              `mapstructure:"cpu" json:"cpu" required:"false" cty:"cpu" hcl:"cpu"`
-	Core                      *int64              `mapstructure:"core" json:"core" required:"false" cty:"core" hcl:"core"`
-	MemoryMB                  *int64              `mapstructure:"memory_mb" json:"memory_mb" required:"false" cty:"memory_mb" hcl:"memory_mb"`
-	UserData                  *string             `mapstructure:"user_data" json:"user_data" required:"false" cty:"user_data" hcl:"user_data"`
-	VMCategories              []FlatCategory      `mapstructure:"vm_categories" required:"false" cty:"vm_categories" hcl:"vm_categories"`
-	Project                   *string             `mapstructure:"project" required:"false" cty:"project" hcl:"project"`
-	GPU                       []FlatGPU           `mapstructure:"gpu" required:"false" cty:"gpu" hcl:"gpu"`
-	SerialPort                *bool               `mapstructure:"serialport" json:"serialport" required:"false" cty:"serialport" hcl:"serialport"`
-	Clean                     *FlatVmClean        `mapstructure:"vm_clean" json:"vm_clean" required:"false" cty:"vm_clean" hcl:"vm_clean"`
-	OvaConfig                 *FlatOvaConfig      `mapstructure:"ova" required:"false" cty:"ova" hcl:"ova"`
-	TemplateConfig            *FlatTemplateConfig `mapstructure:"template" required:"false" cty:"template" hcl:"template"`
-	ForceDeregister           *bool               `mapstructure:"force_deregister" json:"force_deregister" required:"false" cty:"force_deregister" hcl:"force_deregister"`
-	ImageDescription          *string             `mapstructure:"image_description" json:"image_description" required:"false" cty:"image_description" hcl:"image_description"`
-	ImageCategories           []FlatCategory      `mapstructure:"image_categories" required:"false" cty:"image_categories" hcl:"image_categories"`
-	AllowDuplicateImages      *bool               `mapstructure:"allow_duplicate_images" json:"allow_duplicate_images" required:"false" cty:"allow_duplicate_images" hcl:"allow_duplicate_images"`
-	ImageSkip                 *bool               `mapstructure:"image_skip" json:"image_skip" required:"false" cty:"image_skip" hcl:"image_skip"`
-	ImageDelete               *bool               `mapstructure:"image_delete" json:"image_delete" required:"false" cty:"image_delete" hcl:"image_delete"`
-	ImageExport               *bool               `mapstructure:"image_export" json:"image_export" required:"false" cty:"image_export" hcl:"image_export"`
-	FailIfImageExists         *bool               `mapstructure:"fail_if_image_exists" required:"false" cty:"fail_if_image_exists" hcl:"fail_if_image_exists"`
-	VmForceDelete             *bool               `mapstructure:"vm_force_delete" json:"vm_force_delete" required:"false" cty:"vm_force_delete" hcl:"vm_force_delete"`
-	VmRetain                  *bool               `mapstructure:"vm_retain" json:"vm_retain" required:"false" cty:"vm_retain" hcl:"vm_retain"`
-	DisableStopInstance       *bool               `mapstructure:"disable_stop_instance" required:"false" cty:"disable_stop_instance" hcl:"disable_stop_instance"`
-	SkipVMCreateTaskCheck     *bool               `mapstructure:"skip_vm_create_task_check" required:"false" cty:"skip_vm_create_task_check" hcl:"skip_vm_create_task_check"`
+	PackerBuildName            *string                   `mapstructure:"packer_build_name" cty:"packer_build_name" hcl:"packer_build_name"`
+	PackerBuilderType          *string                   `mapstructure:"packer_builder_type" cty:"packer_builder_type" hcl:"packer_builder_type"`
+	PackerCoreVersion          *string                   `mapstructure:"packer_core_version" cty:"packer_core_version" hcl:"packer_core_version"`
+	PackerDebug                *bool                     `mapstructure:"packer_debug" cty:"packer_debug" hcl:"packer_debug"`
+	PackerForce                *bool                     `mapstructure:"packer_force" cty:"packer_force" hcl:"packer_force"`
+	PackerOnError              *string                   `mapstructure:"packer_on_error" cty:"packer_on_error" hcl:"packer_on_error"`
+	PackerUserVars             map[string]string         `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
+	PackerSensitiveVars        []string                  `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
+	WaitTimeout                *string                   `mapstructure:"ip_wait_timeout" cty:"ip_wait_timeout" hcl:"ip_wait_timeout"`
+	SettleTimeout              *string                   `mapstructure:"ip_settle_timeout" cty:"ip_settle_timeout" hcl:"ip_settle_timeout"`
+	WaitAddress                *string                   `mapstructure:"ip_wait_address" cty:"ip_wait_address" hcl:"ip_wait_address"`
+	Type                       *string                   `mapstructure:"communicator" cty:"communicator" hcl:"communicator"`
+	PauseBeforeConnect         *string                   `mapstructure:"pause_before_connecting" cty:"pause_before_connecting" hcl:"pause_before_connecting"`
+	SSHHost                    *string                   `mapstructure:"ssh_host" cty:"ssh_host" hcl:"ssh_host"`
+	SSHPort                    *int                      `mapstructure:"ssh_port" cty:"ssh_port" hcl:"ssh_port"`
+	SSHUsername                *string                   `mapstructure:"ssh_username" cty:"ssh_username" hcl:"ssh_username"`
+	SSHPassword                *string                   `mapstructure:"ssh_password" cty:"ssh_password" hcl:"ssh_password"`
+	SSHKeyPairName             *string                   `mapstructure:"ssh_keypair_name" undocumented:"true" cty:"ssh_keypair_name" hcl:"ssh_keypair_name"`
+	SSHTemporaryKeyPairName    *string                   `mapstructure:"temporary_key_pair_name" undocumented:"true" cty:"temporary_key_pair_name" hcl:"temporary_key_pair_name"`
+	SSHTemporaryKeyPairType    *string                   `mapstructure:"temporary_key_pair_type" cty:"temporary_key_pair_type" hcl:"temporary_key_pair_type"`
+	SSHTemporaryKeyPairBits    *int                      `mapstructure:"temporary_key_pair_bits" cty:"temporary_key_pair_bits" hcl:"temporary_key_pair_bits"`
+	SSHCiphers                 []string                  `mapstructure:"ssh_ciphers" cty:"ssh_ciphers" hcl:"ssh_ciphers"`
+	SSHClearAuthorizedKeys     *bool                     `mapstructure:"ssh_clear_authorized_keys" cty:"ssh_clear_authorized_keys" hcl:"ssh_clear_authorized_keys"`
+	SSHKEXAlgos                []string                  `mapstructure:"ssh_key_exchange_algorithms" cty:"ssh_key_exchange_algorithms" hcl:"ssh_key_exchange_algorithms"`
+	SSHPrivateKeyFile          *string                   `mapstructure:"ssh_private_key_file" undocumented:"true" cty:"ssh_private_key_file" hcl:"ssh_private_key_file"`
+	SSHCertificateFile         *string                   `mapstructure:"ssh_certificate_file" cty:"ssh_certificate_file" hcl:"ssh_certificate_file"`
+	SSHPty                     *bool                     `mapstructure:"ssh_pty" cty:"ssh_pty" hcl:"ssh_pty"`
+	SSHTimeout                 *string                   `mapstructure:"ssh_timeout" cty:"ssh_timeout" hcl:"ssh_timeout"`
+	SSHWaitTimeout             *string                   `mapstructure:"ssh_wait_timeout" undocumented:"true" cty:"ssh_wait_timeout" hcl:"ssh_wait_timeout"`
+	SSHAgentAuth               *bool                     `mapstructure:"ssh_agent_auth" undocumented:"true" cty:"ssh_agent_auth" hcl:"ssh_agent_auth"`
+	SSHDisableAgentForwarding  *bool                     `mapstructure:"ssh_disable_agent_forwarding" cty:"ssh_disable_agent_forwarding" hcl:"ssh_disable_agent_forwarding"`
+	SSHHandshakeAttempts       *int                      `mapstructure:"ssh_handshake_attempts" cty:"ssh_handshake_attempts" hcl:"ssh_handshake_attempts"`
+	SSHBastionHost             *string                   `mapstructure:"ssh_bastion_host" cty:"ssh_bastion_host" hcl:"ssh_bastion_host"`
+	SSHBastionPort             *int                      `mapstructure:"ssh_bastion_port" cty:"ssh_bastion_port" hcl:"ssh_bastion_port"`
+	SSHBastionAgentAuth        *bool                     `mapstructure:"ssh_bastion_agent_auth" cty:"ssh_bastion_agent_auth" hcl:"ssh_bastion_agent_auth"`
+	SSHBastionUsername         *string                   `mapstructure:"ssh_bastion_username" cty:"ssh_bastion_username" hcl:"ssh_bastion_username"`
+	SSHBastionPassword         *string                   `mapstructure:"ssh_bastion_password" cty:"ssh_bastion_password" hcl:"ssh_bastion_password"`
+	SSHBastionInteractive      *bool                     `mapstructure:"ssh_bastion_interactive" cty:"ssh_bastion_interactive" hcl:"ssh_bastion_interactive"`
+	SSHBastionPrivateKeyFile   *string                   `mapstructure:"ssh_bastion_private_key_file" cty:"ssh_bastion_private_key_file" hcl:"ssh_bastion_private_key_file"`
+	SSHBastionCertificateFile  *string                   `mapstructure:"ssh_bastion_certificate_file" cty:"ssh_bastion_certificate_file" hcl:"ssh_bastion_certificate_file"`
+	SSHFileTransferMethod      *string                   `mapstructure:"ssh_file_transfer_method" cty:"ssh_file_transfer_method" hcl:"ssh_file_transfer_method"`
+	SSHProxyHost               *string                   `mapstructure:"ssh_proxy_host" cty:"ssh_proxy_host" hcl:"ssh_proxy_host"`
+	SSHProxyPort               *int                      `mapstructure:"ssh_proxy_port" cty:"ssh_proxy_port" hcl:"ssh_proxy_port"`
+	SSHProxyUsername           *string                   `mapstructure:"ssh_proxy_username" cty:"ssh_proxy_username" hcl:"ssh_proxy_username"`
+	SSHProxyPassword           *string                   `mapstructure:"ssh_proxy_password" cty:"ssh_proxy_password" hcl:"ssh_proxy_password"`
+	SSHKeepAliveInterval       *string                   `mapstructure:"ssh_keep_alive_interval" cty:"ssh_keep_alive_interval" hcl:"ssh_keep_alive_interval"`
+	SSHReadWriteTimeout        *string                   `mapstructure:"ssh_read_write_timeout" cty:"ssh_read_write_timeout" hcl:"ssh_read_write_timeout"`
+	SSHRemoteTunnels           []string                  `mapstructure:"ssh_remote_tunnels" cty:"ssh_remote_tunnels" hcl:"ssh_remote_tunnels"`
+	SSHLocalTunnels            []string                  `mapstructure:"ssh_local_tunnels" cty:"ssh_local_tunnels" hcl:"ssh_local_tunnels"`
+	SSHPublicKey               []byte                    `mapstructure:"ssh_public_key" undocumented:"true" cty:"ssh_public_key" hcl:"ssh_public_key"`
+	SSHPrivateKey              []byte                    `mapstructure:"ssh_private_key" undocumented:"true" cty:"ssh_private_key" hcl:"ssh_private_key"`
+	WinRMUser                  *string                   `mapstructure:"winrm_username" cty:"winrm_username" hcl:"winrm_username"`
+	WinRMPassword              *string                   `mapstructure:"winrm_password" cty:"winrm_password" hcl:"winrm_password"`
+	WinRMHost                  *string                   `mapstructure:"winrm_host" cty:"winrm_host" hcl:"winrm_host"`
+	WinRMNoProxy               *bool                     `mapstructure:"winrm_no_proxy" cty:"winrm_no_proxy" hcl:"winrm_no_proxy"`
+	WinRMPort                  *int                      `mapstructure:"winrm_port" cty:"winrm_port" hcl:"winrm_port"`
+	WinRMTimeout               *string                   `mapstructure:"winrm_timeout" cty:"winrm_timeout" hcl:"winrm_timeout"`
+	WinRMUseSSL                *bool                     `mapstructure:"winrm_use_ssl" cty:"winrm_use_ssl" hcl:"winrm_use_ssl"`
+	WinRMInsecure              *bool                     `mapstructure:"winrm_insecure" cty:"winrm_insecure" hcl:"winrm_insecure"`
+	WinRMUseNTLM               *bool                     `mapstructure:"winrm_use_ntlm" cty:"winrm_use_ntlm" hcl:"winrm_use_ntlm"`
+	BootGroupInterval          *string                   `mapstructure:"boot_keygroup_interval" cty:"boot_keygroup_interval" hcl:"boot_keygroup_interval"`
+	BootWait                   *string                   `mapstructure:"boot_wait" cty:"boot_wait" hcl:"boot_wait"`
+	BootCommand                []string                  `mapstructure:"boot_command" cty:"boot_command" hcl:"boot_command"`
+	DisableVNC                 *bool                     `mapstructure:"disable_vnc" cty:"disable_vnc" hcl:"disable_vnc"`
+	BootKeyInterval            *string                   `mapstructure:"boot_key_interval" cty:"boot_key_interval" hcl:"boot_key_interval"`
+	CDFiles                    []string                  `mapstructure:"cd_files" cty:"cd_files" hcl:"cd_files"`
+	CDContent                  map[string]string         `mapstructure:"cd_content" cty:"cd_content" hcl:"cd_content"`
+	CDLabel                    *string                   `mapstructure:"cd_label" cty:"cd_label" hcl:"cd_label"`
+	ShutdownCommand            *string                   `mapstructure:"shutdown_command" required:"false" cty:"shutdown_command" hcl:"shutdown_command"`
+	ShutdownTimeout            *string                   `mapstructure:"shutdown_timeout" required:"false" cty:"shutdown_timeout" hcl:"shutdown_timeout"`
+	Username                   *string                   `mapstructure:"nutanix_username" required:"false" cty:"nutanix_username" hcl:"nutanix_username"`
+	Password                   *string                   `mapstructure:"nutanix_password" required:"false" cty:"nutanix_password" hcl:"nutanix_password"`
+	Insecure                   *bool                     `mapstructure:"nutanix_insecure" required:"false" cty:"nutanix_insecure" hcl:"nutanix_insecure"`
+	Endpoint                   *string                   `mapstructure:"nutanix_endpoint" required:"true" cty:"nutanix_endpoint" hcl:"nutanix_endpoint"`
+	Port                       *int32                    `mapstructure:"nutanix_port" required:"false" cty:"nutanix_port" hcl:"nutanix_port"`
+	TransferTimeout            *int                      `mapstructure:"nutanix_transfer_timeout" required:"false" cty:"nutanix_transfer_timeout" hcl:"nutanix_transfer_timeout"`
+	VMName                     *string                   `mapstructure:"vm_name" json:"vm_name" required:"false" cty:"vm_name" hcl:"vm_name"`
+	OSType                     *string                   `mapstructure:"os_type" json:"os_type" required:"true" cty:"os_type" hcl:"os_type"`
+	BootType                   *string                   `mapstructure:"boot_type" json:"boot_type" required:"false" cty:"boot_type" hcl:"boot_type"`
+	VTPM                       *FlatVTPM                 `mapstructure:"vtpm" json:"vtpm" required:"false" cty:"vtpm" hcl:"vtpm"`
+	HardwareVirtualization     *bool                     `mapstructure:"hardware_virtualization" json:"hardware_virtualization" required:"false" cty:"hardware_virtualization" hcl:"hardware_virtualization"`
+	BootPriority               *string                   `mapstructure:"boot_priority" json:"boot_priority" required:"false" cty:"boot_priority" hcl:"boot_priority"`
+	BootOrder                  []string                  `mapstructure:"boot_order" json:"boot_order" required:"false" cty:"boot_order" hcl:"boot_order"`
+	VmDisks                    []FlatVmDisk              `mapstructure:"vm_disks" cty:"vm_disks" hcl:"vm_disks"`
+	VmNICs                     []FlatVmNIC               `mapstructure:"vm_nics" cty:"vm_nics" hcl:"vm_nics"`
+	ImageName                  *string                   `mapstructure:"image_name" json:"image_name" required:"false" cty:"image_name" hcl:"image_name"`
+	ClusterUUID                *string                   `mapstructure:"cluster_uuid" json:"cluster_uuid" required:"false" cty:"cluster_uuid" hcl:"cluster_uuid"`
+	ClusterName                *string                   `mapstructure:"cluster_name" json:"cluster_name" required:"false" cty:"cluster_name" hcl:"cluster_name"`
+	ClusterSelector            *FlatClusterSelector      `mapstructure:"cluster_selector" json:"cluster_selector" required:"false" cty:"cluster_selector" hcl:"cluster_selector"`
+	CPU                        *int64                    `mapstructure:"cpu" json:"cpu" required:"false" cty:"cpu" hcl:"cpu"`
+	Core                       *int64                    `mapstructure:"core" json:"core" required:"false" cty:"core" hcl:"core"`
+	MemoryMB                   *int64                    `mapstructure:"memory_mb" json:"memory_mb" required:"false" cty:"memory_mb" hcl:"memory_mb"`
+	UserData                   *string                   `mapstructure:"user_data" json:"user_data" required:"false" cty:"user_data" hcl:"user_data"`
+	VMCategories               []FlatCategory            `mapstructure:"vm_categories" required:"false" cty:"vm_categories" hcl:"vm_categories"`
+	Project                    *string                   `mapstructure:"project" required:"false" cty:"project" hcl:"project"`
+	GPU                        []FlatGPU                 `mapstructure:"gpu" required:"false" cty:"gpu" hcl:"gpu"`
+	SerialPort                 *bool                     `mapstructure:"serialport" json:"serialport" required:"false" cty:"serialport" hcl:"serialport"`
+	Clean                      *FlatVmClean              `mapstructure:"vm_clean" json:"vm_clean" required:"false" cty:"vm_clean" hcl:"vm_clean"`
+	BootSwitch                 *FlatBootSwitch           `mapstructure:"boot_switch" json:"boot_switch" required:"false" cty:"boot_switch" hcl:"boot_switch"`
+	MediaChanges               []FlatMediaChange         `mapstructure:"media_change" json:"media_change" required:"false" cty:"media_change" hcl:"media_change"`
+	SourceTemplateName         *string                   `mapstructure:"source_template_name" json:"source_template_name" required:"false" cty:"source_template_name" hcl:"source_template_name"`
+	SourceTemplateUUID         *string                   `mapstructure:"source_template_uuid" json:"source_template_uuid" required:"false" cty:"source_template_uuid" hcl:"source_template_uuid"`
+	SourceTemplateVersion      *string                   `mapstructure:"source_template_version" json:"source_template_version" required:"false" cty:"source_template_version" hcl:"source_template_version"`
+	SourceVMName               *string                   `mapstructure:"source_vm_name" json:"source_vm_name" required:"false" cty:"source_vm_name" hcl:"source_vm_name"`
+	SourceVMUUID               *string                   `mapstructure:"source_vm_uuid" json:"source_vm_uuid" required:"false" cty:"source_vm_uuid" hcl:"source_vm_uuid"`
+	SourceRecoveryPoint        *string                   `mapstructure:"source_recovery_point" json:"source_recovery_point" required:"false" cty:"source_recovery_point" hcl:"source_recovery_point"`
+	OvaConfig                  *FlatOvaConfig            `mapstructure:"ova" required:"false" cty:"ova" hcl:"ova"`
+	TemplateConfig             *FlatTemplateConfig       `mapstructure:"template" required:"false" cty:"template" hcl:"template"`
+	ForceDeregister            *bool                     `mapstructure:"force_deregister" json:"force_deregister" required:"false" cty:"force_deregister" hcl:"force_deregister"`
+	ImageDescription           *string                   `mapstructure:"image_description" json:"image_description" required:"false" cty:"image_description" hcl:"image_description"`
+	ImageCategories            []FlatCategory            `mapstructure:"image_categories" required:"false" cty:"image_categories" hcl:"image_categories"`
+	ImageClusterNames          []string                  `mapstructure:"image_cluster_names" json:"image_cluster_names" required:"false" cty:"image_cluster_names" hcl:"image_cluster_names"`
+	ImagePlacementPolicy       *FlatImagePlacementPolicy `mapstructure:"image_placement_policy" required:"false" cty:"image_placement_policy" hcl:"image_placement_policy"`
+	AllowDuplicateImages       *bool                     `mapstructure:"allow_duplicate_images" json:"allow_duplicate_images" required:"false" cty:"allow_duplicate_images" hcl:"allow_duplicate_images"`
+	UploadRetries              *int                      `mapstructure:"upload_retries" json:"upload_retries" required:"false" cty:"upload_retries" hcl:"upload_retries"`
+	SourceImageDownloadTimeout *string                   `mapstructure:"source_image_download_timeout" json:"source_image_download_timeout" required:"false" cty:"source_image_download_timeout" hcl:"source_image_download_timeout"`
+	UploadParallelism          *int                      `mapstructure:"upload_parallelism" json:"upload_parallelism" required:"false" cty:"upload_parallelism" hcl:"upload_parallelism"`
+	ImageSkip                  *bool                     `mapstructure:"image_skip" json:"image_skip" required:"false" cty:"image_skip" hcl:"image_skip"`
+	ImageDelete                *bool                     `mapstructure:"image_delete" json:"image_delete" required:"false" cty:"image_delete" hcl:"image_delete"`
+	ImageExport                *bool                     `mapstructure:"image_export" json:"image_export" required:"false" cty:"image_export" hcl:"image_export"`
+	ExportFormat               *string                   `mapstructure:"export_format" json:"export_format" required:"false" cty:"export_format" hcl:"export_format"`
+	ExportCompression          *string                   `mapstructure:"export_compression" json:"export_compression" required:"false" cty:"export_compression" hcl:"export_compression"`
+	ExportRetries              *int                      `mapstructure:"export_retries" json:"export_retries" required:"false" cty:"export_retries" hcl:"export_retries"`
+	ExportParallelism          *int                      `mapstructure:"export_parallelism" json:"export_parallelism" required:"false" cty:"export_parallelism" hcl:"export_parallelism"`
+	ExportDestination          *FlatExportDestination    `mapstructure:"export_destination" required:"false" cty:"export_destination" hcl:"export_destination"`
+	OutputDirectory            *string                   `mapstructure:"output_directory" json:"output_directory" required:"false" cty:"output_directory" hcl:"output_directory"`
+	ForceOutput                *bool                     `mapstructure:"force_output" json:"force_output" required:"false" cty:"force_output" hcl:"force_output"`
+	FailIfImageExists          *bool                     `mapstructure:"fail_if_image_exists" required:"false" cty:"fail_if_image_exists" hcl:"fail_if_image_exists"`
+	VmForceDelete              *bool                     `mapstructure:"vm_force_delete" json:"vm_force_delete" required:"false" cty:"vm_force_delete" hcl:"vm_force_delete"`
+	VmRetain                   *bool                     `mapstructure:"vm_retain" json:"vm_retain" required:"false" cty:"vm_retain" hcl:"vm_retain"`
+	DisableStopInstance        *bool                     `mapstructure:"disable_stop_instance" required:"false" cty:"disable_stop_instance" hcl:"disable_stop_instance"`
+	SkipVMCreateTaskCheck      *bool                     `mapstructure:"skip_vm_create_task_check" required:"false" cty:"skip_vm_create_task_check" hcl:"skip_vm_create_task_check"`
 }
 
 // FlatMapstructure returns a new FlatConfig.
@@ -192,116 +268,175 @@ func (*Config) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec }
 // The decoded values from this spec will then be applied to a FlatConfig.
 func (*FlatConfig) HCL2Spec() map[string]hcldec.Spec {
 	s := map[string]hcldec.Spec{
-		"packer_build_name":            &hcldec.AttrSpec{Name: "packer_build_name", Type: cty.String, Required: false},
-		"packer_builder_type":          &hcldec.AttrSpec{Name: "packer_builder_type", Type: cty.String, Required: false},
-		"packer_core_version":          &hcldec.AttrSpec{Name: "packer_core_version", Type: cty.String, Required: false},
-		"packer_debug":                 &hcldec.AttrSpec{Name: "packer_debug", Type: cty.Bool, Required: false},
-		"packer_force":                 &hcldec.AttrSpec{Name: "packer_force", Type: cty.Bool, Required: false},
-		"packer_on_error":              &hcldec.AttrSpec{Name: "packer_on_error", Type: cty.String, Required: false},
-		"packer_user_variables":        &hcldec.AttrSpec{Name: "packer_user_variables", Type: cty.Map(cty.String), Required: false},
-		"packer_sensitive_variables":   &hcldec.AttrSpec{Name: "packer_sensitive_variables", Type: cty.List(cty.String), Required: false},
-		"ip_wait_timeout":              &hcldec.AttrSpec{Name: "ip_wait_timeout", Type: cty.String, Required: false},
-		"ip_settle_timeout":            &hcldec.AttrSpec{Name: "ip_settle_timeout", Type: cty.String, Required: false},
-		"ip_wait_address":              &hcldec.AttrSpec{Name: "ip_wait_address", Type: cty.String, Required: false},
-		"communicator":                 &hcldec.AttrSpec{Name: "communicator", Type: cty.String, Required: false},
-		"pause_before_connecting":      &hcldec.AttrSpec{Name: "pause_before_connecting", Type: cty.String, Required: false},
-		"ssh_host":                     &hcldec.AttrSpec{Name: "ssh_host", Type: cty.String, Required: false},
-		"ssh_port":                     &hcldec.AttrSpec{Name: "ssh_port", Type: cty.Number, Required: false},
-		"ssh_username":                 &hcldec.AttrSpec{Name: "ssh_username", Type: cty.String, Required: false},
-		"ssh_password":                 &hcldec.AttrSpec{Name: "ssh_password", Type: cty.String, Required: false},
-		"ssh_keypair_name":             &hcldec.AttrSpec{Name: "ssh_keypair_name", Type: cty.String, Required: false},
-		"temporary_key_pair_name":      &hcldec.AttrSpec{Name: "temporary_key_pair_name", Type: cty.String, Required: false},
-		"temporary_key_pair_type":      &hcldec.AttrSpec{Name: "temporary_key_pair_type", Type: cty.String, Required: false},
-		"temporary_key_pair_bits":      &hcldec.AttrSpec{Name: "temporary_key_pair_bits", Type: cty.Number, Required: false},
-		"ssh_ciphers":                  &hcldec.AttrSpec{Name: "ssh_ciphers", Type: cty.List(cty.String), Required: false},
-		"ssh_clear_authorized_keys":    &hcldec.AttrSpec{Name: "ssh_clear_authorized_keys", Type: cty.Bool, Required: false},
-		"ssh_key_exchange_algorithms":  &hcldec.AttrSpec{Name: "ssh_key_exchange_algorithms", Type: cty.List(cty.String), Required: false},
-		"ssh_private_key_file":         &hcldec.AttrSpec{Name: "ssh_private_key_file", Type: cty.String, Required: false},
-		"ssh_certificate_file":         &hcldec.AttrSpec{Name: "ssh_certificate_file", Type: cty.String, Required: false},
-		"ssh_pty":                      &hcldec.AttrSpec{Name: "ssh_pty", Type: cty.Bool, Required: false},
-		"ssh_timeout":                  &hcldec.AttrSpec{Name: "ssh_timeout", Type: cty.String, Required: false},
-		"ssh_wait_timeout":             &hcldec.AttrSpec{Name: "ssh_wait_timeout", Type: cty.String, Required: false},
-		"ssh_agent_auth":               &hcldec.AttrSpec{Name: "ssh_agent_auth", Type: cty.Bool, Required: false},
-		"ssh_disable_agent_forwarding": &hcldec.AttrSpec{Name: "ssh_disable_agent_forwarding", Type: cty.Bool, Required: false},
-		"ssh_handshake_attempts":       &hcldec.AttrSpec{Name: "ssh_handshake_attempts", Type: cty.Number, Required: false},
-		"ssh_bastion_host":             &hcldec.AttrSpec{Name: "ssh_bastion_host", Type: cty.String, Required: false},
-		"ssh_bastion_port":             &hcldec.AttrSpec{Name: "ssh_bastion_port", Type: cty.Number, Required: false},
-		"ssh_bastion_agent_auth":       &hcldec.AttrSpec{Name: "ssh_bastion_agent_auth", Type: cty.Bool, Required: false},
-		"ssh_bastion_username":         &hcldec.AttrSpec{Name: "ssh_bastion_username", Type: cty.String, Required: false},
-		"ssh_bastion_password":         &hcldec.AttrSpec{Name: "ssh_bastion_password", Type: cty.String, Required: false},
-		"ssh_bastion_interactive":      &hcldec.AttrSpec{Name: "ssh_bastion_interactive", Type: cty.Bool, Required: false},
-		"ssh_bastion_private_key_file": &hcldec.AttrSpec{Name: "ssh_bastion_private_key_file", Type: cty.String, Required: false},
-		"ssh_bastion_certificate_file": &hcldec.AttrSpec{Name: "ssh_bastion_certificate_file", Type: cty.String, Required: false},
-		"ssh_file_transfer_method":     &hcldec.AttrSpec{Name: "ssh_file_transfer_method", Type: cty.String, Required: false},
-		"ssh_proxy_host":               &hcldec.AttrSpec{Name: "ssh_proxy_host", Type: cty.String, Required: false},
-		"ssh_proxy_port":               &hcldec.AttrSpec{Name: "ssh_proxy_port", Type: cty.Number, Required: false},
-		"ssh_proxy_username":           &hcldec.AttrSpec{Name: "ssh_proxy_username", Type: cty.String, Required: false},
-		"ssh_proxy_password":           &hcldec.AttrSpec{Name: "ssh_proxy_password", Type: cty.String, Required: false},
-		"ssh_keep_alive_interval":      &hcldec.AttrSpec{Name: "ssh_keep_alive_interval", Type: cty.String, Required: false},
-		"ssh_read_write_timeout":       &hcldec.AttrSpec{Name: "ssh_read_write_timeout", Type: cty.String, Required: false},
-		"ssh_remote_tunnels":           &hcldec.AttrSpec{Name: "ssh_remote_tunnels", Type: cty.List(cty.String), Required: false},
-		"ssh_local_tunnels":            &hcldec.AttrSpec{Name: "ssh_local_tunnels", Type: cty.List(cty.String), Required: false},
-		"ssh_public_key":               &hcldec.AttrSpec{Name: "ssh_public_key", Type: cty.List(cty.Number), Required: false},
-		"ssh_private_key":              &hcldec.AttrSpec{Name: "ssh_private_key", Type: cty.List(cty.Number), Required: false},
-		"winrm_username":               &hcldec.AttrSpec{Name: "winrm_username", Type: cty.String, Required: false},
-		"winrm_password":               &hcldec.AttrSpec{Name: "winrm_password", Type: cty.String, Required: false},
-		"winrm_host":                   &hcldec.AttrSpec{Name: "winrm_host", Type: cty.String, Required: false},
-		"winrm_no_proxy":               &hcldec.AttrSpec{Name: "winrm_no_proxy", Type: cty.Bool, Required: false},
-		"winrm_port":                   &hcldec.AttrSpec{Name: "winrm_port", Type: cty.Number, Required: false},
-		"winrm_timeout":                &hcldec.AttrSpec{Name: "winrm_timeout", Type: cty.String, Required: false},
-		"winrm_use_ssl":                &hcldec.AttrSpec{Name: "winrm_use_ssl", Type: cty.Bool, Required: false},
-		"winrm_insecure":               &hcldec.AttrSpec{Name: "winrm_insecure", Type: cty.Bool, Required: false},
-		"winrm_use_ntlm":               &hcldec.AttrSpec{Name: "winrm_use_ntlm", Type: cty.Bool, Required: false},
-		"boot_keygroup_interval":       &hcldec.AttrSpec{Name: "boot_keygroup_interval", Type: cty.String, Required: false},
-		"boot_wait":                    &hcldec.AttrSpec{Name: "boot_wait", Type: cty.String, Required: false},
-		"boot_command":                 &hcldec.AttrSpec{Name: "boot_command", Type: cty.List(cty.String), Required: false},
-		"disable_vnc":                  &hcldec.AttrSpec{Name: "disable_vnc", Type: cty.Bool, Required: false},
-		"boot_key_interval":            &hcldec.AttrSpec{Name: "boot_key_interval", Type: cty.String, Required: false},
-		"cd_files":                     &hcldec.AttrSpec{Name: "cd_files", Type: cty.List(cty.String), Required: false},
-		"cd_content":                   &hcldec.AttrSpec{Name: "cd_content", Type: cty.Map(cty.String), Required: false},
-		"cd_label":                     &hcldec.AttrSpec{Name: "cd_label", Type: cty.String, Required: false},
-		"shutdown_command":             &hcldec.AttrSpec{Name: "shutdown_command", Type: cty.String, Required: false},
-		"shutdown_timeout":             &hcldec.AttrSpec{Name: "shutdown_timeout", Type: cty.String, Required: false},
-		"nutanix_username":             &hcldec.AttrSpec{Name: "nutanix_username", Type: cty.String, Required: false},
-		"nutanix_password":             &hcldec.AttrSpec{Name: "nutanix_password", Type: cty.String, Required: false},
-		"nutanix_insecure":             &hcldec.AttrSpec{Name: "nutanix_insecure", Type: cty.Bool, Required: false},
-		"nutanix_endpoint":             &hcldec.AttrSpec{Name: "nutanix_endpoint", Type: cty.String, Required: false},
-		"nutanix_port":                 &hcldec.AttrSpec{Name: "nutanix_port", Type: cty.Number, Required: false},
-		"nutanix_transfer_timeout":     &hcldec.AttrSpec{Name: "nutanix_transfer_timeout", Type: cty.Number, Required: false},
-		"vm_name":                      &hcldec.AttrSpec{Name: "vm_name", Type: cty.String, Required: false},
-		"os_type":                      &hcldec.AttrSpec{Name: "os_type", Type: cty.String, Required: false},
-		"boot_type":                    &hcldec.AttrSpec{Name: "boot_type", Type: cty.String, Required: false},
-		"vtpm":                         &hcldec.BlockSpec{TypeName: "vtpm", Nested: hcldec.ObjectSpec((*FlatVTPM)(nil).HCL2Spec())},
-		"hardware_virtualization":      &hcldec.AttrSpec{Name: "hardware_virtualization", Type: cty.Bool, Required: false},
-		"boot_priority":                &hcldec.AttrSpec{Name: "boot_priority", Type: cty.String, Required: false},
-		"vm_disks":                     &hcldec.BlockListSpec{TypeName: "vm_disks", Nested: hcldec.ObjectSpec((*FlatVmDisk)(nil).HCL2Spec())},
-		"vm_nics":                      &hcldec.BlockListSpec{TypeName: "vm_nics", Nested: hcldec.ObjectSpec((*FlatVmNIC)(nil).HCL2Spec())},
-		"image_name":                   &hcldec.AttrSpec{Name: "image_name", Type: cty.String, Required: false},
-		"cluster_uuid":                 &hcldec.AttrSpec{Name: "cluster_uuid", Type: cty.String, Required: false},
-		"cluster_name":                 &hcldec.AttrSpec{Name: "cluster_name", Type: cty.String, Required: false},
-		"cpu":                          &hcldec.AttrSpec{Name: "cpu", Type: cty.Number, Required: false},
-		"core":                         &hcldec.AttrSpec{Name: "core", Type: cty.Number, Required: false},
-		"memory_mb":                    &hcldec.AttrSpec{Name: "memory_mb", Type: cty.Number, Required: false},
-		"user_data":                    &hcldec.AttrSpec{Name: "user_data", Type: cty.String, Required: false},
-		"vm_categories":                &hcldec.BlockListSpec{TypeName: "vm_categories", Nested: hcldec.ObjectSpec((*FlatCategory)(nil).HCL2Spec())},
-		"project":                      &hcldec.AttrSpec{Name: "project", Type: cty.String, Required: false},
-		"gpu":                          &hcldec.BlockListSpec{TypeName: "gpu", Nested: hcldec.ObjectSpec((*FlatGPU)(nil).HCL2Spec())},
-		"serialport":                   &hcldec.AttrSpec{Name: "serialport", Type: cty.Bool, Required: false},
-		"vm_clean":                     &hcldec.BlockSpec{TypeName: "vm_clean", Nested: hcldec.ObjectSpec((*FlatVmClean)(nil).HCL2Spec())},
-		"ova":                          &hcldec.BlockSpec{TypeName: "ova", Nested: hcldec.ObjectSpec((*FlatOvaConfig)(nil).HCL2Spec())},
-		"template":                     &hcldec.BlockSpec{TypeName: "template", Nested: hcldec.ObjectSpec((*FlatTemplateConfig)(nil).HCL2Spec())},
-		"force_deregister":             &hcldec.AttrSpec{Name: "force_deregister", Type: cty.Bool, Required: false},
-		"image_description":            &hcldec.AttrSpec{Name: "image_description", Type: cty.String, Required: false},
-		"image_categories":             &hcldec.BlockListSpec{TypeName: "image_categories", Nested: hcldec.ObjectSpec((*FlatCategory)(nil).HCL2Spec())},
-		"allow_duplicate_images":       &hcldec.AttrSpec{Name: "allow_duplicate_images", Type: cty.Bool, Required: false},
-		"image_skip":                   &hcldec.AttrSpec{Name: "image_skip", Type: cty.Bool, Required: false},
-		"image_delete":                 &hcldec.AttrSpec{Name: "image_delete", Type: cty.Bool, Required: false},
-		"image_export":                 &hcldec.AttrSpec{Name: "image_export", Type: cty.Bool, Required: false},
-		"fail_if_image_exists":         &hcldec.AttrSpec{Name: "fail_if_image_exists", Type: cty.Bool, Required: false},
-		"vm_force_delete":              &hcldec.AttrSpec{Name: "vm_force_delete", Type: cty.Bool, Required: false},
-		"vm_retain":                    &hcldec.AttrSpec{Name: "vm_retain", Type: cty.Bool, Required: false},
-		"disable_stop_instance":        &hcldec.AttrSpec{Name: "disable_stop_instance", Type: cty.Bool, Required: false},
-		"skip_vm_create_task_check":    &hcldec.AttrSpec{Name: "skip_vm_create_task_check", Type: cty.Bool, Required: false},
+		"packer_build_name":             &hcldec.AttrSpec{Name: "packer_build_name", Type: cty.String, Required: false},
+		"packer_builder_type":           &hcldec.AttrSpec{Name: "packer_builder_type", Type: cty.String, Required: false},
+		"packer_core_version":           &hcldec.AttrSpec{Name: "packer_core_version", Type: cty.String, Required: false},
+		"packer_debug":                  &hcldec.AttrSpec{Name: "packer_debug", Type: cty.Bool, Required: false},
+		"packer_force":                  &hcldec.AttrSpec{Name: "packer_force", Type: cty.Bool, Required: false},
+		"packer_on_error":               &hcldec.AttrSpec{Name: "packer_on_error", Type: cty.String, Required: false},
+		"packer_user_variables":         &hcldec.AttrSpec{Name: "packer_user_variables", Type: cty.Map(cty.String), Required: false},
+		"packer_sensitive_variables":    &hcldec.AttrSpec{Name: "packer_sensitive_variables", Type: cty.List(cty.String), Required: false},
+		"ip_wait_timeout":               &hcldec.AttrSpec{Name: "ip_wait_timeout", Type: cty.String, Required: false},
+		"ip_settle_timeout":             &hcldec.AttrSpec{Name: "ip_settle_timeout", Type: cty.String, Required: false},
+		"ip_wait_address":               &hcldec.AttrSpec{Name: "ip_wait_address", Type: cty.String, Required: false},
+		"communicator":                  &hcldec.AttrSpec{Name: "communicator", Type: cty.String, Required: false},
+		"pause_before_connecting":       &hcldec.AttrSpec{Name: "pause_before_connecting", Type: cty.String, Required: false},
+		"ssh_host":                      &hcldec.AttrSpec{Name: "ssh_host", Type: cty.String, Required: false},
+		"ssh_port":                      &hcldec.AttrSpec{Name: "ssh_port", Type: cty.Number, Required: false},
+		"ssh_username":                  &hcldec.AttrSpec{Name: "ssh_username", Type: cty.String, Required: false},
+		"ssh_password":                  &hcldec.AttrSpec{Name: "ssh_password", Type: cty.String, Required: false},
+		"ssh_keypair_name":              &hcldec.AttrSpec{Name: "ssh_keypair_name", Type: cty.String, Required: false},
+		"temporary_key_pair_name":       &hcldec.AttrSpec{Name: "temporary_key_pair_name", Type: cty.String, Required: false},
+		"temporary_key_pair_type":       &hcldec.AttrSpec{Name: "temporary_key_pair_type", Type: cty.String, Required: false},
+		"temporary_key_pair_bits":       &hcldec.AttrSpec{Name: "temporary_key_pair_bits", Type: cty.Number, Required: false},
+		"ssh_ciphers":                   &hcldec.AttrSpec{Name: "ssh_ciphers", Type: cty.List(cty.String), Required: false},
+		"ssh_clear_authorized_keys":     &hcldec.AttrSpec{Name: "ssh_clear_authorized_keys", Type: cty.Bool, Required: false},
+		"ssh_key_exchange_algorithms":   &hcldec.AttrSpec{Name: "ssh_key_exchange_algorithms", Type: cty.List(cty.String), Required: false},
+		"ssh_private_key_file":          &hcldec.AttrSpec{Name: "ssh_private_key_file", Type: cty.String, Required: false},
+		"ssh_certificate_file":          &hcldec.AttrSpec{Name: "ssh_certificate_file", Type: cty.String, Required: false},
+		"ssh_pty":                       &hcldec.AttrSpec{Name: "ssh_pty", Type: cty.Bool, Required: false},
+		"ssh_timeout":                   &hcldec.AttrSpec{Name: "ssh_timeout", Type: cty.String, Required: false},
+		"ssh_wait_timeout":              &hcldec.AttrSpec{Name: "ssh_wait_timeout", Type: cty.String, Required: false},
+		"ssh_agent_auth":                &hcldec.AttrSpec{Name: "ssh_agent_auth", Type: cty.Bool, Required: false},
+		"ssh_disable_agent_forwarding":  &hcldec.AttrSpec{Name: "ssh_disable_agent_forwarding", Type: cty.Bool, Required: false},
+		"ssh_handshake_attempts":        &hcldec.AttrSpec{Name: "ssh_handshake_attempts", Type: cty.Number, Required: false},
+		"ssh_bastion_host":              &hcldec.AttrSpec{Name: "ssh_bastion_host", Type: cty.String, Required: false},
+		"ssh_bastion_port":              &hcldec.AttrSpec{Name: "ssh_bastion_port", Type: cty.Number, Required: false},
+		"ssh_bastion_agent_auth":        &hcldec.AttrSpec{Name: "ssh_bastion_agent_auth", Type: cty.Bool, Required: false},
+		"ssh_bastion_username":          &hcldec.AttrSpec{Name: "ssh_bastion_username", Type: cty.String, Required: false},
+		"ssh_bastion_password":          &hcldec.AttrSpec{Name: "ssh_bastion_password", Type: cty.String, Required: false},
+		"ssh_bastion_interactive":       &hcldec.AttrSpec{Name: "ssh_bastion_interactive", Type: cty.Bool, Required: false},
+		"ssh_bastion_private_key_file":  &hcldec.AttrSpec{Name: "ssh_bastion_private_key_file", Type: cty.String, Required: false},
+		"ssh_bastion_certificate_file":  &hcldec.AttrSpec{Name: "ssh_bastion_certificate_file", Type: cty.String, Required: false},
+		"ssh_file_transfer_method":      &hcldec.AttrSpec{Name: "ssh_file_transfer_method", Type: cty.String, Required: false},
+		"ssh_proxy_host":                &hcldec.AttrSpec{Name: "ssh_proxy_host", Type: cty.String, Required: false},
+		"ssh_proxy_port":                &hcldec.AttrSpec{Name: "ssh_proxy_port", Type: cty.Number, Required: false},
+		"ssh_proxy_username":            &hcldec.AttrSpec{Name: "ssh_proxy_username", Type: cty.String, Required: false},
+		"ssh_proxy_password":            &hcldec.AttrSpec{Name: "ssh_proxy_password", Type: cty.String, Required: false},
+		"ssh_keep_alive_interval":       &hcldec.AttrSpec{Name: "ssh_keep_alive_interval", Type: cty.String, Required: false},
+		"ssh_read_write_timeout":        &hcldec.AttrSpec{Name: "ssh_read_write_timeout", Type: cty.String, Required: false},
+		"ssh_remote_tunnels":            &hcldec.AttrSpec{Name: "ssh_remote_tunnels", Type: cty.List(cty.String), Required: false},
+		"ssh_local_tunnels":             &hcldec.AttrSpec{Name: "ssh_local_tunnels", Type: cty.List(cty.String), Required: false},
+		"ssh_public_key":                &hcldec.AttrSpec{Name: "ssh_public_key", Type: cty.List(cty.Number), Required: false},
+		"ssh_private_key":               &hcldec.AttrSpec{Name: "ssh_private_key", Type: cty.List(cty.Number), Required: false},
+		"winrm_username":                &hcldec.AttrSpec{Name: "winrm_username", Type: cty.String, Required: false},
+		"winrm_password":                &hcldec.AttrSpec{Name: "winrm_password", Type: cty.String, Required: false},
+		"winrm_host":                    &hcldec.AttrSpec{Name: "winrm_host", Type: cty.String, Required: false},
+		"winrm_no_proxy":                &hcldec.AttrSpec{Name: "winrm_no_proxy", Type: cty.Bool, Required: false},
+		"winrm_port":                    &hcldec.AttrSpec{Name: "winrm_port", Type: cty.Number, Required: false},
+		"winrm_timeout":                 &hcldec.AttrSpec{Name: "winrm_timeout", Type: cty.String, Required: false},
+		"winrm_use_ssl":                 &hcldec.AttrSpec{Name: "winrm_use_ssl", Type: cty.Bool, Required: false},
+		"winrm_insecure":                &hcldec.AttrSpec{Name: "winrm_insecure", Type: cty.Bool, Required: false},
+		"winrm_use_ntlm":                &hcldec.AttrSpec{Name: "winrm_use_ntlm", Type: cty.Bool, Required: false},
+		"boot_keygroup_interval":        &hcldec.AttrSpec{Name: "boot_keygroup_interval", Type: cty.String, Required: false},
+		"boot_wait":                     &hcldec.AttrSpec{Name: "boot_wait", Type: cty.String, Required: false},
+		"boot_command":                  &hcldec.AttrSpec{Name: "boot_command", Type: cty.List(cty.String), Required: false},
+		"disable_vnc":                   &hcldec.AttrSpec{Name: "disable_vnc", Type: cty.Bool, Required: false},
+		"boot_key_interval":             &hcldec.AttrSpec{Name: "boot_key_interval", Type: cty.String, Required: false},
+		"cd_files":                      &hcldec.AttrSpec{Name: "cd_files", Type: cty.List(cty.String), Required: false},
+		"cd_content":                    &hcldec.AttrSpec{Name: "cd_content", Type: cty.Map(cty.String), Required: false},
+		"cd_label":                      &hcldec.AttrSpec{Name: "cd_label", Type: cty.String, Required: false},
+		"shutdown_command":              &hcldec.AttrSpec{Name: "shutdown_command", Type: cty.String, Required: false},
+		"shutdown_timeout":              &hcldec.AttrSpec{Name: "shutdown_timeout", Type: cty.String, Required: false},
+		"nutanix_username":              &hcldec.AttrSpec{Name: "nutanix_username", Type: cty.String, Required: false},
+		"nutanix_password":              &hcldec.AttrSpec{Name: "nutanix_password", Type: cty.String, Required: false},
+		"nutanix_insecure":              &hcldec.AttrSpec{Name: "nutanix_insecure", Type: cty.Bool, Required: false},
+		"nutanix_endpoint":              &hcldec.AttrSpec{Name: "nutanix_endpoint", Type: cty.String, Required: false},
+		"nutanix_port":                  &hcldec.AttrSpec{Name: "nutanix_port", Type: cty.Number, Required: false},
+		"nutanix_transfer_timeout":      &hcldec.AttrSpec{Name: "nutanix_transfer_timeout", Type: cty.Number, Required: false},
+		"vm_name":                       &hcldec.AttrSpec{Name: "vm_name", Type: cty.String, Required: false},
+		"os_type":                       &hcldec.AttrSpec{Name: "os_type", Type: cty.String, Required: false},
+		"boot_type":                     &hcldec.AttrSpec{Name: "boot_type", Type: cty.String, Required: false},
+		"vtpm":                          &hcldec.BlockSpec{TypeName: "vtpm", Nested: hcldec.ObjectSpec((*FlatVTPM)(nil).HCL2Spec())},
+		"hardware_virtualization":       &hcldec.AttrSpec{Name: "hardware_virtualization", Type: cty.Bool, Required: false},
+		"boot_priority":                 &hcldec.AttrSpec{Name: "boot_priority", Type: cty.String, Required: false},
+		"boot_order":                    &hcldec.AttrSpec{Name: "boot_order", Type: cty.List(cty.String), Required: false},
+		"vm_disks":                      &hcldec.BlockListSpec{TypeName: "vm_disks", Nested: hcldec.ObjectSpec((*FlatVmDisk)(nil).HCL2Spec())},
+		"vm_nics":                       &hcldec.BlockListSpec{TypeName: "vm_nics", Nested: hcldec.ObjectSpec((*FlatVmNIC)(nil).HCL2Spec())},
+		"image_name":                    &hcldec.AttrSpec{Name: "image_name", Type: cty.String, Required: false},
+		"cluster_uuid":                  &hcldec.AttrSpec{Name: "cluster_uuid", Type: cty.String, Required: false},
+		"cluster_name":                  &hcldec.AttrSpec{Name: "cluster_name", Type: cty.String, Required: false},
+		"cluster_selector":              &hcldec.BlockSpec{TypeName: "cluster_selector", Nested: hcldec.ObjectSpec((*FlatClusterSelector)(nil).HCL2Spec())},
+		"cpu":                           &hcldec.AttrSpec{Name: "cpu", Type: cty.Number, Required: false},
+		"core":                          &hcldec.AttrSpec{Name: "core", Type: cty.Number, Required: false},
+		"memory_mb":                     &hcldec.AttrSpec{Name: "memory_mb", Type: cty.Number, Required: false},
+		"user_data":                     &hcldec.AttrSpec{Name: "user_data", Type: cty.String, Required: false},
+		"vm_categories":                 &hcldec.BlockListSpec{TypeName: "vm_categories", Nested: hcldec.ObjectSpec((*FlatCategory)(nil).HCL2Spec())},
+		"project":                       &hcldec.AttrSpec{Name: "project", Type: cty.String, Required: false},
+		"gpu":                           &hcldec.BlockListSpec{TypeName: "gpu", Nested: hcldec.ObjectSpec((*FlatGPU)(nil).HCL2Spec())},
+		"serialport":                    &hcldec.AttrSpec{Name: "serialport", Type: cty.Bool, Required: false},
+		"vm_clean":                      &hcldec.BlockSpec{TypeName: "vm_clean", Nested: hcldec.ObjectSpec((*FlatVmClean)(nil).HCL2Spec())},
+		"boot_switch":                   &hcldec.BlockSpec{TypeName: "boot_switch", Nested: hcldec.ObjectSpec((*FlatBootSwitch)(nil).HCL2Spec())},
+		"media_change":                  &hcldec.BlockListSpec{TypeName: "media_change", Nested: hcldec.ObjectSpec((*FlatMediaChange)(nil).HCL2Spec())},
+		"source_template_name":          &hcldec.AttrSpec{Name: "source_template_name", Type: cty.String, Required: false},
+		"source_template_uuid":          &hcldec.AttrSpec{Name: "source_template_uuid", Type: cty.String, Required: false},
+		"source_template_version":       &hcldec.AttrSpec{Name: "source_template_version", Type: cty.String, Required: false},
+		"source_vm_name":                &hcldec.AttrSpec{Name: "source_vm_name", Type: cty.String, Required: false},
+		"source_vm_uuid":                &hcldec.AttrSpec{Name: "source_vm_uuid", Type: cty.String, Required: false},
+		"source_recovery_point":         &hcldec.AttrSpec{Name: "source_recovery_point", Type: cty.String, Required: false},
+		"ova":                           &hcldec.BlockSpec{TypeName: "ova", Nested: hcldec.ObjectSpec((*FlatOvaConfig)(nil).HCL2Spec())},
+		"template":                      &hcldec.BlockSpec{TypeName: "template", Nested: hcldec.ObjectSpec((*FlatTemplateConfig)(nil).HCL2Spec())},
+		"force_deregister":              &hcldec.AttrSpec{Name: "force_deregister", Type: cty.Bool, Required: false},
+		"image_description":             &hcldec.AttrSpec{Name: "image_description", Type: cty.String, Required: false},
+		"image_categories":              &hcldec.BlockListSpec{TypeName: "image_categories", Nested: hcldec.ObjectSpec((*FlatCategory)(nil).HCL2Spec())},
+		"image_cluster_names":           &hcldec.AttrSpec{Name: "image_cluster_names", Type: cty.List(cty.String), Required: false},
+		"image_placement_policy":        &hcldec.BlockSpec{TypeName: "image_placement_policy", Nested: hcldec.ObjectSpec((*FlatImagePlacementPolicy)(nil).HCL2Spec())},
+		"allow_duplicate_images":        &hcldec.AttrSpec{Name: "allow_duplicate_images", Type: cty.Bool, Required: false},
+		"upload_retries":                &hcldec.AttrSpec{Name: "upload_retries", Type: cty.Number, Required: false},
+		"source_image_download_timeout": &hcldec.AttrSpec{Name: "source_image_download_timeout", Type: cty.String, Required: false},
+		"upload_parallelism":            &hcldec.AttrSpec{Name: "upload_parallelism", Type: cty.Number, Required: false},
+		"image_skip":                    &hcldec.AttrSpec{Name: "image_skip", Type: cty.Bool, Required: false},
+		"image_delete":                  &hcldec.AttrSpec{Name: "image_delete", Type: cty.Bool, Required: false},
+		"image_export":                  &hcldec.AttrSpec{Name: "image_export", Type: cty.Bool, Required: false},
+		"export_format":                 &hcldec.AttrSpec{Name: "export_format", Type: cty.String, Required: false},
+		"export_compression":            &hcldec.AttrSpec{Name: "export_compression", Type: cty.String, Required: false},
+		"export_retries":                &hcldec.AttrSpec{Name: "export_retries", Type: cty.Number, Required: false},
+		"export_parallelism":            &hcldec.AttrSpec{Name: "export_parallelism", Type: cty.Number, Required: false},
+		"export_destination":            &hcldec.BlockSpec{TypeName: "export_destination", Nested: hcldec.ObjectSpec((*FlatExportDestination)(nil).HCL2Spec())},
+		"output_directory":              &hcldec.AttrSpec{Name: "output_directory", Type: cty.String, Required: false},
+		"force_output":                  &hcldec.AttrSpec{Name: "force_output", Type: cty.Bool, Required: false},
+		"fail_if_image_exists":          &hcldec.AttrSpec{Name: "fail_if_image_exists", Type: cty.Bool, Required: false},
+		"vm_force_delete":               &hcldec.AttrSpec{Name: "vm_force_delete", Type: cty.Bool, Required: false},
+		"vm_retain":                     &hcldec.AttrSpec{Name: "vm_retain", Type: cty.Bool, Required: false},
+		"disable_stop_instance":         &hcldec.AttrSpec{Name: "disable_stop_instance", Type: cty.Bool, Required: false},
+		"skip_vm_create_task_check":     &hcldec.AttrSpec{Name: "skip_vm_create_task_check", Type: cty.Bool, Required: false},
+	}
+	return s
+}
+
+// FlatExportDestination is an auto-generated flat version of ExportDestination.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatExportDestination struct {
+	URL               *string `mapstructure:"url" json:"url" required:"false" cty:"url" hcl:"url"`
+	Endpoint          *string `mapstructure:"endpoint" json:"endpoint" required:"false" cty:"endpoint" hcl:"endpoint"`
+	Region            *string `mapstructure:"region" json:"region" required:"false" cty:"region" hcl:"region"`
+	AccessKey         *string `mapstructure:"access_key" json:"access_key" required:"false" cty:"access_key" hcl:"access_key"`
+	SecretKey         *string `mapstructure:"secret_key" json:"secret_key" required:"false" cty:"secret_key" hcl:"secret_key"`
+	SessionToken      *string `mapstructure:"session_token" json:"session_token" required:"false" cty:"session_token" hcl:"session_token"`
+	ChecksumAlgorithm *string `mapstructure:"checksum_algorithm" json:"checksum_algorithm" required:"false" cty:"checksum_algorithm" hcl:"checksum_algorithm"`
+	PartSize          *int    `mapstructure:"part_size" json:"part_size" required:"false" cty:"part_size" hcl:"part_size"`
+}
+
+// FlatMapstructure returns a new FlatExportDestination.
+// FlatExportDestination is an auto-generated flat version of ExportDestination.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*ExportDestination) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatExportDestination)
+}
+
+// HCL2Spec returns the hcl spec of a ExportDestination.
+// This spec is used by HCL to read the fields of ExportDestination.
+// The decoded values from this spec will then be applied to a FlatExportDestination.
+func (*FlatExportDestination) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"url":                &hcldec.AttrSpec{Name: "url", Type: cty.String, Required: false},
+		"endpoint":           &hcldec.AttrSpec{Name: "endpoint", Type: cty.String, Required: false},
+		"region":             &hcldec.AttrSpec{Name: "region", Type: cty.String, Required: false},
+		"access_key":         &hcldec.AttrSpec{Name: "access_key", Type: cty.String, Required: false},
+		"secret_key":         &hcldec.AttrSpec{Name: "secret_key", Type: cty.String, Required: false},
+		"session_token":      &hcldec.AttrSpec{Name: "session_token", Type: cty.String, Required: false},
+		"checksum_algorithm": &hcldec.AttrSpec{Name: "checksum_algorithm", Type: cty.String, Required: false},
+		"part_size":          &hcldec.AttrSpec{Name: "part_size", Type: cty.Number, Required: false},
 	}
 	return s
 }
@@ -329,13 +464,106 @@ func (*FlatGPU) HCL2Spec() map[string]hcldec.Spec {
 	return s
 }
 
+// FlatImageFilter is an auto-generated flat version of ImageFilter.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatImageFilter struct {
+	NameRegex    *string        `mapstructure:"name_regex" json:"name_regex" required:"false" cty:"name_regex" hcl:"name_regex"`
+	Categories   []FlatCategory `mapstructure:"categories" json:"categories" required:"false" cty:"categories" hcl:"categories"`
+	ImageType    *string        `mapstructure:"image_type" json:"image_type" required:"false" cty:"image_type" hcl:"image_type"`
+	CreatedAfter *string        `mapstructure:"created_after" json:"created_after" required:"false" cty:"created_after" hcl:"created_after"`
+	MostRecent   *bool          `mapstructure:"most_recent" json:"most_recent" required:"false" cty:"most_recent" hcl:"most_recent"`
+}
+
+// FlatMapstructure returns a new FlatImageFilter.
+// FlatImageFilter is an auto-generated flat version of ImageFilter.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*ImageFilter) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatImageFilter)
+}
+
+// HCL2Spec returns the hcl spec of a ImageFilter.
+// This spec is used by HCL to read the fields of ImageFilter.
+// The decoded values from this spec will then be applied to a FlatImageFilter.
+func (*FlatImageFilter) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"name_regex":    &hcldec.AttrSpec{Name: "name_regex", Type: cty.String, Required: false},
+		"categories":    &hcldec.BlockListSpec{TypeName: "categories", Nested: hcldec.ObjectSpec((*FlatCategory)(nil).HCL2Spec())},
+		"image_type":    &hcldec.AttrSpec{Name: "image_type", Type: cty.String, Required: false},
+		"created_after": &hcldec.AttrSpec{Name: "created_after", Type: cty.String, Required: false},
+		"most_recent":   &hcldec.AttrSpec{Name: "most_recent", Type: cty.Bool, Required: false},
+	}
+	return s
+}
+
+// FlatImagePlacementPolicy is an auto-generated flat version of ImagePlacementPolicy.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatImagePlacementPolicy struct {
+	Name              *string        `mapstructure:"name" json:"name" required:"false" cty:"name" hcl:"name"`
+	ClusterCategories []FlatCategory `mapstructure:"cluster_categories" json:"cluster_categories" required:"false" cty:"cluster_categories" hcl:"cluster_categories"`
+	PlacementType     *string        `mapstructure:"placement_type" json:"placement_type" required:"false" cty:"placement_type" hcl:"placement_type"`
+}
+
+// FlatMapstructure returns a new FlatImagePlacementPolicy.
+// FlatImagePlacementPolicy is an auto-generated flat version of ImagePlacementPolicy.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*ImagePlacementPolicy) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatImagePlacementPolicy)
+}
+
+// HCL2Spec returns the hcl spec of a ImagePlacementPolicy.
+// This spec is used by HCL to read the fields of ImagePlacementPolicy.
+// The decoded values from this spec will then be applied to a FlatImagePlacementPolicy.
+func (*FlatImagePlacementPolicy) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"name":               &hcldec.AttrSpec{Name: "name", Type: cty.String, Required: false},
+		"cluster_categories": &hcldec.BlockListSpec{TypeName: "cluster_categories", Nested: hcldec.ObjectSpec((*FlatCategory)(nil).HCL2Spec())},
+		"placement_type":     &hcldec.AttrSpec{Name: "placement_type", Type: cty.String, Required: false},
+	}
+	return s
+}
+
+// FlatMediaChange is an auto-generated flat version of MediaChange.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatMediaChange struct {
+	Name            *string `mapstructure:"name" json:"name" required:"false" cty:"name" hcl:"name"`
+	Stage           *string `mapstructure:"stage" json:"stage" required:"false" cty:"stage" hcl:"stage"`
+	Action          *string `mapstructure:"action" json:"action" required:"false" cty:"action" hcl:"action"`
+	CdromIndex      *int    `mapstructure:"cdrom_index" json:"cdrom_index" required:"false" cty:"cdrom_index" hcl:"cdrom_index"`
+	SourceImageName *string `mapstructure:"source_image_name" json:"source_image_name" required:"false" cty:"source_image_name" hcl:"source_image_name"`
+	SourceImageUUID *string `mapstructure:"source_image_uuid" json:"source_image_uuid" required:"false" cty:"source_image_uuid" hcl:"source_image_uuid"`
+}
+
+// FlatMapstructure returns a new FlatMediaChange.
+// FlatMediaChange is an auto-generated flat version of MediaChange.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*MediaChange) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatMediaChange)
+}
+
+// HCL2Spec returns the hcl spec of a MediaChange.
+// This spec is used by HCL to read the fields of MediaChange.
+// The decoded values from this spec will then be applied to a FlatMediaChange.
+func (*FlatMediaChange) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"name":              &hcldec.AttrSpec{Name: "name", Type: cty.String, Required: false},
+		"stage":             &hcldec.AttrSpec{Name: "stage", Type: cty.String, Required: false},
+		"action":            &hcldec.AttrSpec{Name: "action", Type: cty.String, Required: false},
+		"cdrom_index":       &hcldec.AttrSpec{Name: "cdrom_index", Type: cty.Number, Required: false},
+		"source_image_name": &hcldec.AttrSpec{Name: "source_image_name", Type: cty.String, Required: false},
+		"source_image_uuid": &hcldec.AttrSpec{Name: "source_image_uuid", Type: cty.String, Required: false},
+	}
+	return s
+}
+
 // FlatOvaConfig is an auto-generated flat version of OvaConfig.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatOvaConfig struct {
-	Export *bool   `mapstructure:"export" json:"export" required:"false" cty:"export" hcl:"export"`
-	Create *bool   `mapstructure:"create" json:"create" required:"false" cty:"create" hcl:"create"`
-	Format *string `mapstructure:"format" json:"format" required:"false" cty:"format" hcl:"format"`
-	Name   *string `mapstructure:"name" json:"name" required:"false" cty:"name" hcl:"name"`
+	Export            *bool   `mapstructure:"export" json:"export" required:"false" cty:"export" hcl:"export"`
+	Create            *bool   `mapstructure:"create" json:"create" required:"false" cty:"create" hcl:"create"`
+	Format            *string `mapstructure:"format" json:"format" required:"false" cty:"format" hcl:"format"`
+	Name              *string `mapstructure:"name" json:"name" required:"false" cty:"name" hcl:"name"`
+	OnConflict        *string `mapstructure:"on_conflict" json:"on_conflict" required:"false" cty:"on_conflict" hcl:"on_conflict"`
+	DeleteAfterExport *bool   `mapstructure:"delete_after_export" json:"delete_after_export" required:"false" cty:"delete_after_export" hcl:"delete_after_export"`
 }
 
 // FlatMapstructure returns a new FlatOvaConfig.
@@ -350,10 +578,12 @@ func (*OvaConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spe
 // The decoded values from this spec will then be applied to a FlatOvaConfig.
 func (*FlatOvaConfig) HCL2Spec() map[string]hcldec.Spec {
 	s := map[string]hcldec.Spec{
-		"export": &hcldec.AttrSpec{Name: "export", Type: cty.Bool, Required: false},
-		"create": &hcldec.AttrSpec{Name: "create", Type: cty.Bool, Required: false},
-		"format": &hcldec.AttrSpec{Name: "format", Type: cty.String, Required: false},
-		"name":   &hcldec.AttrSpec{Name: "name", Type: cty.String, Required: false},
+		"export":              &hcldec.AttrSpec{Name: "export", Type: cty.Bool, Required: false},
+		"create":              &hcldec.AttrSpec{Name: "create", Type: cty.Bool, Required: false},
+		"format":              &hcldec.AttrSpec{Name: "format", Type: cty.String, Required: false},
+		"name":                &hcldec.AttrSpec{Name: "name", Type: cty.String, Required: false},
+		"on_conflict":         &hcldec.AttrSpec{Name: "on_conflict", Type: cty.String, Required: false},
+		"delete_after_export": &hcldec.AttrSpec{Name: "delete_after_export", Type: cty.Bool, Required: false},
 	}
 	return s
 }
@@ -361,9 +591,19 @@ func (*FlatOvaConfig) HCL2Spec() map[string]hcldec.Spec {
 // FlatTemplateConfig is an auto-generated flat version of TemplateConfig.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatTemplateConfig struct {
-	Create      *bool   `mapstructure:"create" json:"create" required:"false" cty:"create" hcl:"create"`
-	Name        *string `mapstructure:"name" json:"name" required:"false" cty:"name" hcl:"name"`
-	Description *string `mapstructure:"description" json:"description" required:"false" cty:"description" hcl:"description"`
+	Create             *bool                           `mapstructure:"create" json:"create" required:"false" cty:"create" hcl:"create"`
+	Name               *string                         `mapstructure:"name" json:"name" required:"false" cty:"name" hcl:"name"`
+	Description        *string                         `mapstructure:"description" json:"description" required:"false" cty:"description" hcl:"description"`
+	UpdateExisting     *bool                           `mapstructure:"update_existing" json:"update_existing" required:"false" cty:"update_existing" hcl:"update_existing"`
+	VersionName        *string                         `mapstructure:"version_name" json:"version_name" required:"false" cty:"version_name" hcl:"version_name"`
+	VersionDescription *string                         `mapstructure:"version_description" json:"version_description" required:"false" cty:"version_description" hcl:"version_description"`
+	Active             *bool                           `mapstructure:"active" json:"active" required:"false" cty:"active" hcl:"active"`
+	Retention          *int                            `mapstructure:"retention" json:"retention" required:"false" cty:"retention" hcl:"retention"`
+	Categories         []FlatCategory                  `mapstructure:"categories" json:"categories" required:"false" cty:"categories" hcl:"categories"`
+	GuestCustomization *FlatTemplateGuestCustomization `mapstructure:"guest_customization" json:"guest_customization" required:"false" cty:"guest_customization" hcl:"guest_customization"`
+	Project            *string                         `mapstructure:"project" json:"project" required:"false" cty:"project" hcl:"project"`
+	Owner              *string                         `mapstructure:"owner" json:"owner" required:"false" cty:"owner" hcl:"owner"`
+	OnConflict         *string                         `mapstructure:"on_conflict" json:"on_conflict" required:"false" cty:"on_conflict" hcl:"on_conflict"`
 }
 
 // FlatMapstructure returns a new FlatTemplateConfig.
@@ -378,9 +618,44 @@ func (*TemplateConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hclde
 // The decoded values from this spec will then be applied to a FlatTemplateConfig.
 func (*FlatTemplateConfig) HCL2Spec() map[string]hcldec.Spec {
 	s := map[string]hcldec.Spec{
-		"create":      &hcldec.AttrSpec{Name: "create", Type: cty.Bool, Required: false},
-		"name":        &hcldec.AttrSpec{Name: "name", Type: cty.String, Required: false},
-		"description": &hcldec.AttrSpec{Name: "description", Type: cty.String, Required: false},
+		"create":              &hcldec.AttrSpec{Name: "create", Type: cty.Bool, Required: false},
+		"name":                &hcldec.AttrSpec{Name: "name", Type: cty.String, Required: false},
+		"description":         &hcldec.AttrSpec{Name: "description", Type: cty.String, Required: false},
+		"update_existing":     &hcldec.AttrSpec{Name: "update_existing", Type: cty.Bool, Required: false},
+		"version_name":        &hcldec.AttrSpec{Name: "version_name", Type: cty.String, Required: false},
+		"version_description": &hcldec.AttrSpec{Name: "version_description", Type: cty.String, Required: false},
+		"active":              &hcldec.AttrSpec{Name: "active", Type: cty.Bool, Required: false},
+		"retention":           &hcldec.AttrSpec{Name: "retention", Type: cty.Number, Required: false},
+		"categories":          &hcldec.BlockListSpec{TypeName: "categories", Nested: hcldec.ObjectSpec((*FlatCategory)(nil).HCL2Spec())},
+		"guest_customization": &hcldec.BlockSpec{TypeName: "guest_customization", Nested: hcldec.ObjectSpec((*FlatTemplateGuestCustomization)(nil).HCL2Spec())},
+		"project":             &hcldec.AttrSpec{Name: "project", Type: cty.String, Required: false},
+		"owner":               &hcldec.AttrSpec{Name: "owner", Type: cty.String, Required: false},
+		"on_conflict":         &hcldec.AttrSpec{Name: "on_conflict", Type: cty.String, Required: false},
+	}
+	return s
+}
+
+// FlatTemplateGuestCustomization is an auto-generated flat version of TemplateGuestCustomization.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatTemplateGuestCustomization struct {
+	UserData    *string `mapstructure:"user_data" json:"user_data" required:"false" cty:"user_data" hcl:"user_data"`
+	Overridable *bool   `mapstructure:"overridable" json:"overridable" required:"false" cty:"overridable" hcl:"overridable"`
+}
+
+// FlatMapstructure returns a new FlatTemplateGuestCustomization.
+// FlatTemplateGuestCustomization is an auto-generated flat version of TemplateGuestCustomization.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*TemplateGuestCustomization) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatTemplateGuestCustomization)
+}
+
+// HCL2Spec returns the hcl spec of a TemplateGuestCustomization.
+// This spec is used by HCL to read the fields of TemplateGuestCustomization.
+// The decoded values from this spec will then be applied to a FlatTemplateGuestCustomization.
+func (*FlatTemplateGuestCustomization) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"user_data":   &hcldec.AttrSpec{Name: "user_data", Type: cty.String, Required: false},
+		"overridable": &hcldec.AttrSpec{Name: "overridable", Type: cty.Bool, Required: false},
 	}
 	return s
 }
@@ -411,7 +686,13 @@ func (*FlatVTPM) HCL2Spec() map[string]hcldec.Spec {
 // FlatVmClean is an auto-generated flat version of VmClean.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatVmClean struct {
-	Cdrom *bool `mapstructure:"cdrom" json:"cdrom" required:"false" cty:"cdrom" hcl:"cdrom"`
+	Cdrom              *bool `mapstructure:"cdrom" json:"cdrom" required:"false" cty:"cdrom" hcl:"cdrom"`
+	Nics               *bool `mapstructure:"nics" json:"nics" required:"false" cty:"nics" hcl:"nics"`
+	ResetMac           *bool `mapstructure:"reset_mac" json:"reset_mac" required:"false" cty:"reset_mac" hcl:"reset_mac"`
+	SerialPort         *bool `mapstructure:"serialport" json:"serialport" required:"false" cty:"serialport" hcl:"serialport"`
+	GPU                *bool `mapstructure:"gpu" json:"gpu" required:"false" cty:"gpu" hcl:"gpu"`
+	Categories         *bool `mapstructure:"categories" json:"categories" required:"false" cty:"categories" hcl:"categories"`
+	GuestCustomization *bool `mapstructure:"guest_customization" json:"guest_customization" required:"false" cty:"guest_customization" hcl:"guest_customization"`
 }
 
 // FlatMapstructure returns a new FlatVmClean.
@@ -426,7 +707,13 @@ func (*VmClean) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec
 // The decoded values from this spec will then be applied to a FlatVmClean.
 func (*FlatVmClean) HCL2Spec() map[string]hcldec.Spec {
 	s := map[string]hcldec.Spec{
-		"cdrom": &hcldec.AttrSpec{Name: "cdrom", Type: cty.Bool, Required: false},
+		"cdrom":               &hcldec.AttrSpec{Name: "cdrom", Type: cty.Bool, Required: false},
+		"nics":                &hcldec.AttrSpec{Name: "nics", Type: cty.Bool, Required: false},
+		"reset_mac":           &hcldec.AttrSpec{Name: "reset_mac", Type: cty.Bool, Required: false},
+		"serialport":          &hcldec.AttrSpec{Name: "serialport", Type: cty.Bool, Required: false},
+		"gpu":                 &hcldec.AttrSpec{Name: "gpu", Type: cty.Bool, Required: false},
+		"categories":          &hcldec.AttrSpec{Name: "categories", Type: cty.Bool, Required: false},
+		"guest_customization": &hcldec.AttrSpec{Name: "guest_customization", Type: cty.Bool, Required: false},
 	}
 	return s
 }
@@ -434,26 +721,36 @@ func (*FlatVmClean) HCL2Spec() map[string]hcldec.Spec {
 // FlatVmConfig is an auto-generated flat version of VmConfig.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatVmConfig struct {
-	VMName                 *string        `mapstructure:"vm_name" json:"vm_name" required:"false" cty:"vm_name" hcl:"vm_name"`
-	OSType                 *string        `mapstructure:"os_type" json:"os_type" required:"true" cty:"os_type" hcl:"os_type"`
-	BootType               *string        `mapstructure:"boot_type" json:"boot_type" required:"false" cty:"boot_type" hcl:"boot_type"`
-	VTPM                   *FlatVTPM      `mapstructure:"vtpm" json:"vtpm" required:"false" cty:"vtpm" hcl:"vtpm"`
-	HardwareVirtualization *bool          `mapstructure:"hardware_virtualization" json:"hardware_virtualization" required:"false" cty:"hardware_virtualization" hcl:"hardware_virtualization"`
-	BootPriority           *string        `mapstructure:"boot_priority" json:"boot_priority" required:"false" cty:"boot_priority" hcl:"boot_priority"`
-	VmDisks                []FlatVmDisk   `mapstructure:"vm_disks" cty:"vm_disks" hcl:"vm_disks"`
-	VmNICs                 []FlatVmNIC    `mapstructure:"vm_nics" cty:"vm_nics" hcl:"vm_nics"`
-	ImageName              *string        `mapstructure:"image_name" json:"image_name" required:"false" cty:"image_name" hcl:"image_name"`
-	ClusterUUID            *string        `mapstructure:"cluster_uuid" json:"cluster_uuid" required:"false" cty:"cluster_uuid" hcl:"cluster_uuid"`
-	ClusterName            *string        `mapstructure:"cluster_name" json:"cluster_name" required:"false" cty:"cluster_name" hcl:"cluster_name"`
-	CPU                    *int64         `mapstructure:"cpu" json:"cpu" required:"false" cty:"cpu" hcl:"cpu"`
-	Core                   *int64         `mapstructure:"core" json:"core" required:"false" cty:"core" hcl:"core"`
-	MemoryMB               *int64         `mapstructure:"memory_mb" json:"memory_mb" required:"false" cty:"memory_mb" hcl:"memory_mb"`
-	UserData               *string        `mapstructure:"user_data" json:"user_data" required:"false" cty:"user_data" hcl:"user_data"`
-	VMCategories           []FlatCategory `mapstructure:"vm_categories" required:"false" cty:"vm_categories" hcl:"vm_categories"`
-	Project                *string        `mapstructure:"project" required:"false" cty:"project" hcl:"project"`
-	GPU                    []FlatGPU      `mapstructure:"gpu" required:"false" cty:"gpu" hcl:"gpu"`
-	SerialPort             *bool          `mapstructure:"serialport" json:"serialport" required:"false" cty:"serialport" hcl:"serialport"`
-	Clean                  *FlatVmClean   `mapstructure:"vm_clean" json:"vm_clean" required:"false" cty:"vm_clean" hcl:"vm_clean"`
+	VMName                 *string              `mapstructure:"vm_name" json:"vm_name" required:"false" cty:"vm_name" hcl:"vm_name"`
+	OSType                 *string              `mapstructure:"os_type" json:"os_type" required:"true" cty:"os_type" hcl:"os_type"`
+	BootType               *string              `mapstructure:"boot_type" json:"boot_type" required:"false" cty:"boot_type" hcl:"boot_type"`
+	VTPM                   *FlatVTPM            `mapstructure:"vtpm" json:"vtpm" required:"false" cty:"vtpm" hcl:"vtpm"`
+	HardwareVirtualization *bool                `mapstructure:"hardware_virtualization" json:"hardware_virtualization" required:"false" cty:"hardware_virtualization" hcl:"hardware_virtualization"`
+	BootPriority           *string              `mapstructure:"boot_priority" json:"boot_priority" required:"false" cty:"boot_priority" hcl:"boot_priority"`
+	BootOrder              []string             `mapstructure:"boot_order" json:"boot_order" required:"false" cty:"boot_order" hcl:"boot_order"`
+	VmDisks                []FlatVmDisk         `mapstructure:"vm_disks" cty:"vm_disks" hcl:"vm_disks"`
+	VmNICs                 []FlatVmNIC          `mapstructure:"vm_nics" cty:"vm_nics" hcl:"vm_nics"`
+	ImageName              *string              `mapstructure:"image_name" json:"image_name" required:"false" cty:"image_name" hcl:"image_name"`
+	ClusterUUID            *string              `mapstructure:"cluster_uuid" json:"cluster_uuid" required:"false" cty:"cluster_uuid" hcl:"cluster_uuid"`
+	ClusterName            *string              `mapstructure:"cluster_name" json:"cluster_name" required:"false" cty:"cluster_name" hcl:"cluster_name"`
+	ClusterSelector        *FlatClusterSelector `mapstructure:"cluster_selector" json:"cluster_selector" required:"false" cty:"cluster_selector" hcl:"cluster_selector"`
+	CPU                    *int64               `mapstructure:"cpu" json:"cpu" required:"false" cty:"cpu" hcl:"cpu"`
+	Core                   *int64               `mapstructure:"core" json:"core" required:"false" cty:"core" hcl:"core"`
+	MemoryMB               *int64               `mapstructure:"memory_mb" json:"memory_mb" required:"false" cty:"memory_mb" hcl:"memory_mb"`
+	UserData               *string              `mapstructure:"user_data" json:"user_data" required:"false" cty:"user_data" hcl:"user_data"`
+	VMCategories           []FlatCategory       `mapstructure:"vm_categories" required:"false" cty:"vm_categories" hcl:"vm_categories"`
+	Project                *string              `mapstructure:"project" required:"false" cty:"project" hcl:"project"`
+	GPU                    []FlatGPU            `mapstructure:"gpu" required:"false" cty:"gpu" hcl:"gpu"`
+	SerialPort             *bool                `mapstructure:"serialport" json:"serialport" required:"false" cty:"serialport" hcl:"serialport"`
+	Clean                  *FlatVmClean         `mapstructure:"vm_clean" json:"vm_clean" required:"false" cty:"vm_clean" hcl:"vm_clean"`
+	BootSwitch             *FlatBootSwitch      `mapstructure:"boot_switch" json:"boot_switch" required:"false" cty:"boot_switch" hcl:"boot_switch"`
+	MediaChanges           []FlatMediaChange    `mapstructure:"media_change" json:"media_change" required:"false" cty:"media_change" hcl:"media_change"`
+	SourceTemplateName     *string              `mapstructure:"source_template_name" json:"source_template_name" required:"false" cty:"source_template_name" hcl:"source_template_name"`
+	SourceTemplateUUID     *string              `mapstructure:"source_template_uuid" json:"source_template_uuid" required:"false" cty:"source_template_uuid" hcl:"source_template_uuid"`
+	SourceTemplateVersion  *string              `mapstructure:"source_template_version" json:"source_template_version" required:"false" cty:"source_template_version" hcl:"source_template_version"`
+	SourceVMName           *string              `mapstructure:"source_vm_name" json:"source_vm_name" required:"false" cty:"source_vm_name" hcl:"source_vm_name"`
+	SourceVMUUID           *string              `mapstructure:"source_vm_uuid" json:"source_vm_uuid" required:"false" cty:"source_vm_uuid" hcl:"source_vm_uuid"`
+	SourceRecoveryPoint    *string              `mapstructure:"source_recovery_point" json:"source_recovery_point" required:"false" cty:"source_recovery_point" hcl:"source_recovery_point"`
 }
 
 // FlatMapstructure returns a new FlatVmConfig.
@@ -474,11 +771,13 @@ func (*FlatVmConfig) HCL2Spec() map[string]hcldec.Spec {
 		"vtpm":                    &hcldec.BlockSpec{TypeName: "vtpm", Nested: hcldec.ObjectSpec((*FlatVTPM)(nil).HCL2Spec())},
 		"hardware_virtualization": &hcldec.AttrSpec{Name: "hardware_virtualization", Type: cty.Bool, Required: false},
 		"boot_priority":           &hcldec.AttrSpec{Name: "boot_priority", Type: cty.String, Required: false},
+		"boot_order":              &hcldec.AttrSpec{Name: "boot_order", Type: cty.List(cty.String), Required: false},
 		"vm_disks":                &hcldec.BlockListSpec{TypeName: "vm_disks", Nested: hcldec.ObjectSpec((*FlatVmDisk)(nil).HCL2Spec())},
 		"vm_nics":                 &hcldec.BlockListSpec{TypeName: "vm_nics", Nested: hcldec.ObjectSpec((*FlatVmNIC)(nil).HCL2Spec())},
 		"image_name":              &hcldec.AttrSpec{Name: "image_name", Type: cty.String, Required: false},
 		"cluster_uuid":            &hcldec.AttrSpec{Name: "cluster_uuid", Type: cty.String, Required: false},
 		"cluster_name":            &hcldec.AttrSpec{Name: "cluster_name", Type: cty.String, Required: false},
+		"cluster_selector":        &hcldec.BlockSpec{TypeName: "cluster_selector", Nested: hcldec.ObjectSpec((*FlatClusterSelector)(nil).HCL2Spec())},
 		"cpu":                     &hcldec.AttrSpec{Name: "cpu", Type: cty.Number, Required: false},
 		"core":                    &hcldec.AttrSpec{Name: "core", Type: cty.Number, Required: false},
 		"memory_mb":               &hcldec.AttrSpec{Name: "memory_mb", Type: cty.Number, Required: false},
@@ -488,6 +787,14 @@ func (*FlatVmConfig) HCL2Spec() map[string]hcldec.Spec {
 		"gpu":                     &hcldec.BlockListSpec{TypeName: "gpu", Nested: hcldec.ObjectSpec((*FlatGPU)(nil).HCL2Spec())},
 		"serialport":              &hcldec.AttrSpec{Name: "serialport", Type: cty.Bool, Required: false},
 		"vm_clean":                &hcldec.BlockSpec{TypeName: "vm_clean", Nested: hcldec.ObjectSpec((*FlatVmClean)(nil).HCL2Spec())},
+		"boot_switch":             &hcldec.BlockSpec{TypeName: "boot_switch", Nested: hcldec.ObjectSpec((*FlatBootSwitch)(nil).HCL2Spec())},
+		"media_change":            &hcldec.BlockListSpec{TypeName: "media_change", Nested: hcldec.ObjectSpec((*FlatMediaChange)(nil).HCL2Spec())},
+		"source_template_name":    &hcldec.AttrSpec{Name: "source_template_name", Type: cty.String, Required: false},
+		"source_template_uuid":    &hcldec.AttrSpec{Name: "source_template_uuid", Type: cty.String, Required: false},
+		"source_template_version": &hcldec.AttrSpec{Name: "source_template_version", Type: cty.String, Required: false},
+		"source_vm_name":          &hcldec.AttrSpec{Name: "source_vm_name", Type: cty.String, Required: false},
+		"source_vm_uuid":          &hcldec.AttrSpec{Name: "source_vm_uuid", Type: cty.String, Required: false},
+		"source_recovery_point":   &hcldec.AttrSpec{Name: "source_recovery_point", Type: cty.String, Required: false},
 	}
 	return s
 }
@@ -495,17 +802,25 @@ func (*FlatVmConfig) HCL2Spec() map[string]hcldec.Spec {
 // FlatVmDisk is an auto-generated flat version of VmDisk.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatVmDisk struct {
-	ImageType               *string `mapstructure:"image_type" json:"image_type" required:"false" cty:"image_type" hcl:"image_type"`
-	SourceImageName         *string `mapstructure:"source_image_name" json:"source_image_name" required:"false" cty:"source_image_name" hcl:"source_image_name"`
-	SourceImageUUID         *string `mapstructure:"source_image_uuid" json:"source_image_uuid" required:"false" cty:"source_image_uuid" hcl:"source_image_uuid"`
-	SourceImageURI          *string `mapstructure:"source_image_uri" json:"source_image_uri" required:"false" cty:"source_image_uri" hcl:"source_image_uri"`
-	SourceImagePath         *string `mapstructure:"source_image_path" json:"source_image_path" required:"false" cty:"source_image_path" hcl:"source_image_path"`
-	SourceImageChecksum     *string `mapstructure:"source_image_checksum" json:"source_image_checksum" required:"false" cty:"source_image_checksum" hcl:"source_image_checksum"`
-	SourceImageChecksumType *string `mapstructure:"source_image_checksum_type" json:"source_image_checksum_type" required:"false" cty:"source_image_checksum_type" hcl:"source_image_checksum_type"`
-	SourceImageDelete       *bool   `mapstructure:"source_image_delete" json:"source_image_delete" required:"false" cty:"source_image_delete" hcl:"source_image_delete"`
-	SourceImageForce        *bool   `mapstructure:"source_image_force" json:"source_image_force" required:"false" cty:"source_image_force" hcl:"source_image_force"`
-	DiskSizeGB              *int64  `mapstructure:"disk_size_gb" json:"disk_size_gb" required:"false" cty:"disk_size_gb" hcl:"disk_size_gb"`
-	StorageContainerUUID    *string `mapstructure:"storage_container_uuid" json:"storage_container_uuid" required:"false" cty:"storage_container_uuid" hcl:"storage_container_uuid"`
+	ImageType               *string          `mapstructure:"image_type" json:"image_type" required:"false" cty:"image_type" hcl:"image_type"`
+	SourceImageName         *string          `mapstructure:"source_image_name" json:"source_image_name" required:"false" cty:"source_image_name" hcl:"source_image_name"`
+	SourceImageUUID         *string          `mapstructure:"source_image_uuid" json:"source_image_uuid" required:"false" cty:"source_image_uuid" hcl:"source_image_uuid"`
+	SourceImageURI          *string          `mapstructure:"source_image_uri" json:"source_image_uri" required:"false" cty:"source_image_uri" hcl:"source_image_uri"`
+	SourceImagePath         *string          `mapstructure:"source_image_path" json:"source_image_path" required:"false" cty:"source_image_path" hcl:"source_image_path"`
+	SourceImageFilter       *FlatImageFilter `mapstructure:"source_image_filter" json:"source_image_filter" required:"false" cty:"source_image_filter" hcl:"source_image_filter"`
+	SourceImageChecksum     *string          `mapstructure:"source_image_checksum" json:"source_image_checksum" required:"false" cty:"source_image_checksum" hcl:"source_image_checksum"`
+	SourceImageChecksumType *string          `mapstructure:"source_image_checksum_type" json:"source_image_checksum_type" required:"false" cty:"source_image_checksum_type" hcl:"source_image_checksum_type"`
+	SourceImageDelete       *bool            `mapstructure:"source_image_delete" json:"source_image_delete" required:"false" cty:"source_image_delete" hcl:"source_image_delete"`
+	SourceImageForce        *bool            `mapstructure:"source_image_force" json:"source_image_force" required:"false" cty:"source_image_force" hcl:"source_image_force"`
+	DiskSizeGB              *int64           `mapstructure:"disk_size_gb" json:"disk_size_gb" required:"false" cty:"disk_size_gb" hcl:"disk_size_gb"`
+	StorageContainerUUID    *string          `mapstructure:"storage_container_uuid" json:"storage_container_uuid" required:"false" cty:"storage_container_uuid" hcl:"storage_container_uuid"`
+	StorageContainerName    *string          `mapstructure:"storage_container_name" json:"storage_container_name" required:"false" cty:"storage_container_name" hcl:"storage_container_name"`
+	BusType                 *string          `mapstructure:"bus_type" json:"bus_type" required:"false" cty:"bus_type" hcl:"bus_type"`
+	Index                   *int             `mapstructure:"index" json:"index" required:"false" cty:"index" hcl:"index"`
+	SaveImage               *bool            `mapstructure:"save_image" json:"save_image" required:"false" cty:"save_image" hcl:"save_image"`
+	OutputImageName         *string          `mapstructure:"output_image_name" json:"output_image_name" required:"false" cty:"output_image_name" hcl:"output_image_name"`
+	OutputImageDescription  *string          `mapstructure:"output_image_description" json:"output_image_description" required:"false" cty:"output_image_description" hcl:"output_image_description"`
+	OutputImageCategories   []FlatCategory   `mapstructure:"output_image_categories" json:"output_image_categories" required:"false" cty:"output_image_categories" hcl:"output_image_categories"`
 }
 
 // FlatMapstructure returns a new FlatVmDisk.
@@ -525,12 +840,20 @@ func (*FlatVmDisk) HCL2Spec() map[string]hcldec.Spec {
 		"source_image_uuid":          &hcldec.AttrSpec{Name: "source_image_uuid", Type: cty.String, Required: false},
 		"source_image_uri":           &hcldec.AttrSpec{Name: "source_image_uri", Type: cty.String, Required: false},
 		"source_image_path":          &hcldec.AttrSpec{Name: "source_image_path", Type: cty.String, Required: false},
+		"source_image_filter":        &hcldec.BlockSpec{TypeName: "source_image_filter", Nested: hcldec.ObjectSpec((*FlatImageFilter)(nil).HCL2Spec())},
 		"source_image_checksum":      &hcldec.AttrSpec{Name: "source_image_checksum", Type: cty.String, Required: false},
 		"source_image_checksum_type": &hcldec.AttrSpec{Name: "source_image_checksum_type", Type: cty.String, Required: false},
 		"source_image_delete":        &hcldec.AttrSpec{Name: "source_image_delete", Type: cty.Bool, Required: false},
 		"source_image_force":         &hcldec.AttrSpec{Name: "source_image_force", Type: cty.Bool, Required: false},
 		"disk_size_gb":               &hcldec.AttrSpec{Name: "disk_size_gb", Type: cty.Number, Required: false},
 		"storage_container_uuid":     &hcldec.AttrSpec{Name: "storage_container_uuid", Type: cty.String, Required: false},
+		"storage_container_name":     &hcldec.AttrSpec{Name: "storage_container_name", Type: cty.String, Required: false},
+		"bus_type":                   &hcldec.AttrSpec{Name: "bus_type", Type: cty.String, Required: false},
+		"index":                      &hcldec.AttrSpec{Name: "index", Type: cty.Number, Required: false},
+		"save_image":                 &hcldec.AttrSpec{Name: "save_image", Type: cty.Bool, Required: false},
+		"output_image_name":          &hcldec.AttrSpec{Name: "output_image_name", Type: cty.String, Required: false},
+		"output_image_description":   &hcldec.AttrSpec{Name: "output_image_description", Type: cty.String, Required: false},
+		"output_image_categories":    &hcldec.BlockListSpec{TypeName: "output_image_categories", Nested: hcldec.ObjectSpec((*FlatCategory)(nil).HCL2Spec())},
 	}
 	return s
 }
@@ -0,0 +1,395 @@
+package nutanix
+
+import (
+	"strings"
+	"testing"
+)
+
+// testConfig returns a minimal valid raw configuration, building from an empty disk.
+func testConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"nutanix_username": "admin",
+		"nutanix_password": "password",
+		"nutanix_endpoint": "prism.example.com",
+		"cluster_name":     "cluster",
+		"os_type":          "Linux",
+		"ssh_username":     "packer",
+		"vm_disks": []map[string]interface{}{
+			{"image_type": "DISK", "disk_size_gb": 40},
+		},
+		"vm_nics": []map[string]interface{}{
+			{"subnet_name": "subnet"},
+		},
+	}
+}
+
+// testPrepare runs Config.Prepare on the minimal configuration changed by update.
+func testPrepare(update func(raw map[string]interface{})) (*Config, error) {
+	raw := testConfig()
+	if update != nil {
+		update(raw)
+	}
+	c := &Config{}
+	_, err := c.Prepare(raw)
+	return c, err
+}
+
+func TestConfigPrepareMinimal(t *testing.T) {
+	if _, err := testPrepare(nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+// prepareTest is a Config.Prepare case: the change to the minimal configuration and the
+// expected error, empty when the configuration is valid.
+type prepareTest struct {
+	name    string
+	update  func(raw map[string]interface{})
+	wantErr string
+}
+
+func runPrepareTests(t *testing.T, tests []prepareTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testPrepare(tt.update)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error %q, got none", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error %q, got %q", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestConfigPrepareSourceTemplate(t *testing.T) {
+	runPrepareTests(t, []prepareTest{
+		{
+			name: "template name",
+			update: func(raw map[string]interface{}) {
+				delete(raw, "vm_disks")
+				raw["source_template_name"] = "base"
+			},
+		},
+		{
+			name: "template uuid and version",
+			update: func(raw map[string]interface{}) {
+				delete(raw, "vm_disks")
+				raw["source_template_uuid"] = "uuid"
+				raw["source_template_version"] = "v2"
+			},
+		},
+		{
+			name: "template name and uuid",
+			update: func(raw map[string]interface{}) {
+				delete(raw, "vm_disks")
+				raw["source_template_name"] = "base"
+				raw["source_template_uuid"] = "uuid"
+			},
+			wantErr: "source_template_name and source_template_uuid are mutually exclusive",
+		},
+		{
+			name: "template with vm_disks",
+			update: func(raw map[string]interface{}) {
+				raw["source_template_name"] = "base"
+			},
+			wantErr: "vm_disks, cd_files and cd_content can not be used with a source template",
+		},
+		{
+			name: "template version without template",
+			update: func(raw map[string]interface{}) {
+				raw["source_template_version"] = "v2"
+			},
+			wantErr: "source_template_version can be used only with source_template_name or source_template_uuid",
+		},
+	})
+}
+
+func TestConfigPrepareSourceTemplateKeepsSizing(t *testing.T) {
+	c, err := testPrepare(func(raw map[string]interface{}) {
+		delete(raw, "vm_disks")
+		raw["source_template_name"] = "base"
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if c.CPU != 0 || c.MemoryMB != 0 {
+		t.Errorf("template sizing overridden: cpu %d, memory_mb %d", c.CPU, c.MemoryMB)
+	}
+}
+
+func TestConfigPrepareSourceVM(t *testing.T) {
+	runPrepareTests(t, []prepareTest{
+		{
+			name: "vm name",
+			update: func(raw map[string]interface{}) {
+				delete(raw, "vm_disks")
+				raw["source_vm_name"] = "reference"
+			},
+		},
+		{
+			name: "vm uuid and recovery point",
+			update: func(raw map[string]interface{}) {
+				delete(raw, "vm_disks")
+				raw["source_vm_uuid"] = "uuid"
+				raw["source_recovery_point"] = "rp"
+			},
+		},
+		{
+			name: "vm name and uuid",
+			update: func(raw map[string]interface{}) {
+				delete(raw, "vm_disks")
+				raw["source_vm_name"] = "reference"
+				raw["source_vm_uuid"] = "uuid"
+			},
+			wantErr: "source_vm_name and source_vm_uuid are mutually exclusive",
+		},
+		{
+			name: "vm with vm_disks",
+			update: func(raw map[string]interface{}) {
+				raw["source_vm_name"] = "reference"
+			},
+			wantErr: "vm_disks, cd_files and cd_content can not be used with a source VM",
+		},
+		{
+			name: "vm and template",
+			update: func(raw map[string]interface{}) {
+				delete(raw, "vm_disks")
+				raw["source_vm_name"] = "reference"
+				raw["source_template_name"] = "base"
+			},
+			wantErr: "source_template_* and source_vm_* are mutually exclusive",
+		},
+		{
+			name: "vm with template version",
+			update: func(raw map[string]interface{}) {
+				delete(raw, "vm_disks")
+				raw["source_vm_name"] = "reference"
+				raw["source_template_version"] = "v2"
+			},
+			wantErr: "source_template_version can be used only with source_template_name or source_template_uuid",
+		},
+		{
+			name: "recovery point without vm",
+			update: func(raw map[string]interface{}) {
+				raw["source_recovery_point"] = "rp"
+			},
+			wantErr: "source_recovery_point can be used only with source_vm_name or source_vm_uuid",
+		},
+		{
+			name: "recovery point with template",
+			update: func(raw map[string]interface{}) {
+				delete(raw, "vm_disks")
+				raw["source_template_name"] = "base"
+				raw["source_recovery_point"] = "rp"
+			},
+			wantErr: "source_recovery_point can be used only with source_vm_name or source_vm_uuid",
+		},
+	})
+}
+
+func TestConfigPrepareTemplateUpdateExisting(t *testing.T) {
+	runPrepareTests(t, []prepareTest{
+		{
+			name: "update existing with retention and inactive version",
+			update: func(raw map[string]interface{}) {
+				raw["template"] = map[string]interface{}{
+					"create":          true,
+					"update_existing": true,
+					"retention":       3,
+					"active":          false,
+				}
+			},
+		},
+		{
+			name: "retention without update existing",
+			update: func(raw map[string]interface{}) {
+				raw["template"] = map[string]interface{}{
+					"create":    true,
+					"retention": 3,
+				}
+			},
+			wantErr: "template.retention can be used only with template.update_existing",
+		},
+		{
+			name: "negative retention",
+			update: func(raw map[string]interface{}) {
+				raw["template"] = map[string]interface{}{
+					"create":          true,
+					"update_existing": true,
+					"retention":       -1,
+				}
+			},
+			wantErr: "template.retention must be >= 0",
+		},
+		{
+			name: "inactive version without update existing",
+			update: func(raw map[string]interface{}) {
+				raw["template"] = map[string]interface{}{
+					"create": true,
+					"active": false,
+				}
+			},
+			wantErr: "template.active = false can be used only with template.update_existing",
+		},
+		{
+			name: "on conflict with update existing",
+			update: func(raw map[string]interface{}) {
+				raw["template"] = map[string]interface{}{
+					"create":          true,
+					"update_existing": true,
+					"on_conflict":     "replace",
+				}
+			},
+			wantErr: "template.on_conflict can't be used with template.update_existing",
+		},
+	})
+}
+
+func TestConfigPrepareOnConflict(t *testing.T) {
+	var tests []prepareTest
+	for _, value := range []string{"fail", "replace", "skip"} {
+		tests = append(tests, prepareTest{
+			name: "template " + value,
+			update: func(raw map[string]interface{}) {
+				raw["template"] = map[string]interface{}{"create": true, "on_conflict": value}
+			},
+		}, prepareTest{
+			name: "ova " + value,
+			update: func(raw map[string]interface{}) {
+				raw["ova"] = map[string]interface{}{"create": true, "on_conflict": value}
+			},
+		})
+	}
+	tests = append(tests, prepareTest{
+		name: "template invalid",
+		update: func(raw map[string]interface{}) {
+			raw["template"] = map[string]interface{}{"create": true, "on_conflict": "overwrite"}
+		},
+		wantErr: "template.on_conflict should be 'fail', 'replace' or 'skip'",
+	}, prepareTest{
+		name: "ova invalid",
+		update: func(raw map[string]interface{}) {
+			raw["ova"] = map[string]interface{}{"create": true, "on_conflict": "overwrite"}
+		},
+		wantErr: "ova.on_conflict should be 'fail', 'replace' or 'skip'",
+	})
+
+	runPrepareTests(t, tests)
+}
+
+func TestConfigPrepareClusterSelector(t *testing.T) {
+	runPrepareTests(t, []prepareTest{
+		{
+			name: "names",
+			update: func(raw map[string]interface{}) {
+				delete(raw, "cluster_name")
+				raw["cluster_selector"] = map[string]interface{}{"names": []string{"cluster-a", "cluster-b"}}
+			},
+		},
+		{
+			name: "categories",
+			update: func(raw map[string]interface{}) {
+				delete(raw, "cluster_name")
+				raw["cluster_selector"] = map[string]interface{}{
+					"categories": []map[string]interface{}{{"key": "Environment", "value": "Build"}},
+				}
+			},
+		},
+		{
+			name: "explicit cluster overrides selector of a source VM",
+			update: func(raw map[string]interface{}) {
+				delete(raw, "vm_disks")
+				raw["source_vm_name"] = "reference"
+				raw["cluster_selector"] = map[string]interface{}{"names": []string{"cluster-a"}}
+			},
+		},
+		{
+			name: "no cluster",
+			update: func(raw map[string]interface{}) {
+				delete(raw, "cluster_name")
+			},
+			wantErr: "missing cluster_name, cluster_uuid or cluster_selector",
+		},
+		{
+			name: "names and categories",
+			update: func(raw map[string]interface{}) {
+				delete(raw, "cluster_name")
+				raw["cluster_selector"] = map[string]interface{}{
+					"names":      []string{"cluster-a"},
+					"categories": []map[string]interface{}{{"key": "Environment", "value": "Build"}},
+				}
+			},
+			wantErr: "cluster_selector names and categories are mutually exclusive",
+		},
+		{
+			name: "category without value",
+			update: func(raw map[string]interface{}) {
+				delete(raw, "cluster_name")
+				raw["cluster_selector"] = map[string]interface{}{
+					"categories": []map[string]interface{}{{"key": "Environment"}},
+				}
+			},
+			wantErr: "cluster_selector categories entries need both key and value",
+		},
+		{
+			name: "source VM",
+			update: func(raw map[string]interface{}) {
+				delete(raw, "cluster_name")
+				delete(raw, "vm_disks")
+				raw["source_vm_name"] = "reference"
+				raw["cluster_selector"] = map[string]interface{}{"names": []string{"cluster-a"}}
+			},
+			wantErr: "cluster_selector can't be used with source_vm_name, source_vm_uuid or source_recovery_point",
+		},
+	})
+}
+
+func TestConfigPrepareMediaChange(t *testing.T) {
+	eject := func(index int) []map[string]interface{} {
+		return []map[string]interface{}{{"action": "eject", "cdrom_index": index}}
+	}
+
+	runPrepareTests(t, []prepareTest{
+		{
+			name: "iso disk",
+			update: func(raw map[string]interface{}) {
+				raw["vm_disks"] = []map[string]interface{}{
+					{"image_type": "DISK", "disk_size_gb": 40},
+					{"image_type": "ISO_IMAGE", "source_image_name": "installer"},
+				}
+				raw["media_change"] = eject(0)
+			},
+		},
+		{
+			name: "unknown cd-rom",
+			update: func(raw map[string]interface{}) {
+				raw["media_change"] = eject(2)
+			},
+			wantErr: "cdrom_index 2 does not match a CD-ROM of the VM",
+		},
+		{
+			name: "cd-rom of the source template",
+			update: func(raw map[string]interface{}) {
+				delete(raw, "vm_disks")
+				raw["source_template_name"] = "base"
+				raw["media_change"] = eject(2)
+			},
+		},
+		{
+			name: "cd-rom of the source vm",
+			update: func(raw map[string]interface{}) {
+				delete(raw, "vm_disks")
+				raw["source_vm_name"] = "reference"
+				raw["media_change"] = eject(2)
+			},
+		},
+	})
+}
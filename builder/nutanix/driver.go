@@ -3,6 +3,7 @@ package nutanix
 import (
 	"context"
 	"crypto/tls"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"log"
@@ -11,11 +12,12 @@ import (
 	"os"
 	"path"
 	"path/filepath"
-	"sort"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/hashicorp/packer-plugin-sdk/packer"
 	client "github.com/nutanix-cloud-native/prism-go-client"
 	"github.com/nutanix-cloud-native/prism-go-client/converged"
 	convergedv4 "github.com/nutanix-cloud-native/prism-go-client/converged/v4"
@@ -24,16 +26,21 @@ import (
 	v4 "github.com/nutanix-cloud-native/prism-go-client/v4"
 	clusterModels "github.com/nutanix/ntnx-api-golang-clients/clustermgmt-go-client/v4/models/clustermgmt/v4/config"
 	subnetModels "github.com/nutanix/ntnx-api-golang-clients/networking-go-client/v4/models/networking/v4/config"
+	vmmApi "github.com/nutanix/ntnx-api-golang-clients/vmm-go-client/v4/api"
 	commonv1 "github.com/nutanix/ntnx-api-golang-clients/vmm-go-client/v4/models/common/v1/config"
+	vmmPrismModels "github.com/nutanix/ntnx-api-golang-clients/vmm-go-client/v4/models/prism/v4/config"
 	vmmModels "github.com/nutanix/ntnx-api-golang-clients/vmm-go-client/v4/models/vmm/v4/ahv/config"
 	imageModels "github.com/nutanix/ntnx-api-golang-clients/vmm-go-client/v4/models/vmm/v4/content"
+	imagesConfig "github.com/nutanix/ntnx-api-golang-clients/vmm-go-client/v4/models/vmm/v4/images/config"
 )
 
 const (
 	defaultImageBuiltDescription = "built by Packer"
 	defaultImageDLDescription    = "added by Packer"
-	vmDescription                = "Packer vm building image %s"
-	ntnxAPIKeyHeaderName         = "X-ntnx-api-key"
+	// defaultImageUploadDescription is completed with the SHA-256 of the uploaded file
+	defaultImageUploadDescription = "uploaded by Packer, sha256:%s"
+	vmDescription                 = "Packer vm building image %s"
+	ntnxAPIKeyHeaderName          = "X-ntnx-api-key"
 )
 
 const (
@@ -51,17 +58,32 @@ type Driver interface {
 	GetVM(context.Context, string) (*nutanixInstance, error)
 	GetHost(context.Context, string) (*nutanixHost, error)
 	PowerOff(context.Context, string) error
-	CreateImageURL(context.Context, VmDisk, VmConfig) (*nutanixImage, error)
-	CreateImageFile(context.Context, string, VmConfig) (*nutanixImage, error)
+	CreateImageURL(context.Context, VmDisk, VmConfig, packer.Ui) (*nutanixImage, error)
+	CreateImageFile(context.Context, string, string, string, VmConfig, packer.Ui) (*nutanixImage, error)
 	DeleteImage(context.Context, string) error
 	GetImage(context.Context, string) (*nutanixImage, error)
-	CreateTemplate(context.Context, string, TemplateConfig) error
-	CreateOVA(context.Context, string, string, string) error
-	ExportOVA(context.Context, string) (string, error)
-	ExportImage(context.Context, string) (io.ReadCloser, error)
-	SaveVMDisk(context.Context, string, int, []Category) (*nutanixImage, error)
+	CreateTemplate(context.Context, string, TemplateConfig) (*nutanixTemplate, error)
+	DeployTemplate(context.Context, *vmmModels.Vm) (*nutanixInstance, *nutanixTemplate, error)
+	CloneVM(context.Context, *vmmModels.Vm) (*nutanixInstance, error)
+	CreateOVA(context.Context, string, string, string) (string, error)
+	ExportOVA(context.Context, string, int64) (io.ReadCloser, int64, error)
+	FindOVAs(context.Context, string) ([]string, error)
+	DeleteOVA(context.Context, string) error
+	FindTemplates(context.Context, string) ([]string, error)
+	DeleteTemplate(context.Context, string) error
+	ExportImage(context.Context, string, int64) (io.ReadCloser, int64, error)
+	SaveVMDisk(context.Context, string, string, string, []Category) (*nutanixImage, error)
+	EnsureImagePlacementPolicy(context.Context, ImagePlacementPolicy, []Category) error
+	SelectCluster(context.Context, ClusterSelector, VmConfig, packer.Ui) (*clusterCapacity, error)
 	WaitForShutdown(string, <-chan struct{}) bool
 	CleanCD(context.Context, string) error
+	CleanNICs(context.Context, string, bool) error
+	CleanSerialPorts(context.Context, string) error
+	CleanGPUs(context.Context, string) error
+	CleanCategories(context.Context, string) error
+	CleanGuestCustomization(context.Context, string, []string) error
+	EjectCdRom(context.Context, string, string) error
+	InsertCdRom(context.Context, string, string, string) error
 	PowerOn(context.Context, string) error
 	GenerateConsoleToken(context.Context, string) (token, wsUri string, err error)
 }
@@ -135,6 +157,60 @@ func (n *nutanixInstance) Disks() []vmmModels.Disk {
 	return n.vm.Disks
 }
 
+// GuestCustomizationCdRoms returns the IDs of the CD-ROMs loaded with media not backed by an
+// image. Right after the VM is created, those are the CD-ROMs Prism attaches to hand the guest
+// customization over to the guest.
+func (n *nutanixInstance) GuestCustomizationCdRoms() []string {
+	if n.vm == nil {
+		return nil
+	}
+
+	var cdromIDs []string
+	for _, cdrom := range n.vm.CdRoms {
+		if cdrom.ExtId != nil && cdromLoaded(&cdrom) && cdromImageUUID(&cdrom) == "" {
+			cdromIDs = append(cdromIDs, *cdrom.ExtId)
+		}
+	}
+	return cdromIDs
+}
+
+type nutanixTemplate struct {
+	template *imageModels.Template
+	version  *imageModels.TemplateVersionSpec
+}
+
+// UUID returns the template's external ID (UUID)
+func (n *nutanixTemplate) UUID() string {
+	if n.template != nil && n.template.ExtId != nil {
+		return *n.template.ExtId
+	}
+	return ""
+}
+
+// Name returns the template's name
+func (n *nutanixTemplate) Name() string {
+	if n.template != nil && n.template.TemplateName != nil {
+		return *n.template.TemplateName
+	}
+	return ""
+}
+
+// VersionUUID returns the external ID (UUID) of the template version
+func (n *nutanixTemplate) VersionUUID() string {
+	if n.version != nil && n.version.ExtId != nil {
+		return *n.version.ExtId
+	}
+	return ""
+}
+
+// VersionName returns the name of the template version
+func (n *nutanixTemplate) VersionName() string {
+	if n.version != nil && n.version.VersionName != nil {
+		return *n.version.VersionName
+	}
+	return ""
+}
+
 type nutanixHost struct {
 	host *clusterModels.Host
 }
@@ -164,7 +240,8 @@ func (n *nutanixHost) ClusterUUID() string {
 }
 
 type nutanixImage struct {
-	image *imageModels.Image // V4 native type
+	image  *imageModels.Image // V4 native type
+	reused bool               // existing image reused instead of created
 }
 
 // UUID returns the image's external ID (UUID)
@@ -191,6 +268,11 @@ func (n *nutanixImage) SizeBytes() int64 {
 	return 0
 }
 
+// Checksum returns the checksum type and hex digest of the image, empty when Prism has none
+func (n *nutanixImage) Checksum() (string, string) {
+	return imageChecksum(n.image)
+}
+
 // getConfigCreds returns the credentials for connecting to Prism Central
 func (d *NutanixDriver) getConfigCreds() client.Credentials {
 	return client.Credentials{
@@ -245,6 +327,23 @@ func (d *NutanixDriver) getV4TransferClient() (*convergedv4.Client, error) {
 	return v4Client, nil
 }
 
+// getV4SDKClient returns the V4 SDK client from the shared cache (creating it if needed).
+func (d *NutanixDriver) getV4SDKClient() (*v4.Client, error) {
+	cacheParams := &v4CacheParams{
+		endpoint: d.ClusterConfig.Endpoint,
+		port:     d.ClusterConfig.Port,
+		username: d.ClusterConfig.Username,
+		password: d.ClusterConfig.Password,
+		insecure: d.ClusterConfig.Insecure,
+	}
+
+	v4Client, err := v4SDKClientCache.GetOrCreate(cacheParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get or create V4 SDK client: %w", err)
+	}
+	return v4Client, nil
+}
+
 func findProjectByName(ctx context.Context, conn *v3.Client, name string) (*v3.Project, error) {
 	resp, err := conn.V3.ListAllProject(ctx, "")
 	if err != nil {
@@ -278,7 +377,7 @@ func findProjectByName(ctx context.Context, conn *v3.Client, name string) (*v3.P
 // sourceImageExists checks if an image with the given name exists using V4 API.
 // It verifies images are ready (SizeBytes > 0) and optionally validates the checksum
 // to detect corrupt/partial images. Matching priority: name+URL > name+checksum > name-only.
-func sourceImageExists(ctx context.Context, v4Client *convergedv4.Client, name, uri, expectedChecksum string, allowDuplicates bool) (*imageModels.Image, error) {
+func sourceImageExists(ctx context.Context, v4Client *convergedv4.Client, name, uri, expectedChecksumType, expectedChecksum string, allowDuplicates bool) (*imageModels.Image, error) {
 	images, err := v4Client.Images.List(ctx, converged.WithFilter(fmt.Sprintf("name eq '%s'", name)))
 	if err != nil {
 		return nil, err
@@ -303,25 +402,12 @@ func sourceImageExists(ctx context.Context, v4Client *convergedv4.Client, name,
 			continue
 		}
 
-		// Verify checksum if both expected and actual are available
-		if expectedChecksum != "" && img.Checksum != nil {
-			if checksumValue := img.Checksum.GetValue(); checksumValue != nil {
-				actualDigest := ""
-				switch cs := checksumValue.(type) {
-				case imageModels.ImageSha256Checksum:
-					if cs.HexDigest != nil {
-						actualDigest = *cs.HexDigest
-					}
-				case imageModels.ImageSha1Checksum:
-					if cs.HexDigest != nil {
-						actualDigest = *cs.HexDigest
-					}
-				}
-				if actualDigest != "" && !strings.EqualFold(actualDigest, expectedChecksum) {
-					log.Printf("skipping image '%s' (extId: %s) - checksum mismatch (expected: %s, actual: %s)",
-						name, *img.ExtId, expectedChecksum, actualDigest)
-					continue
-				}
+		// Verify checksum if both expected and actual are available, with the same type
+		if expectedChecksum != "" {
+			if actualType, actualDigest := imageChecksum(img); actualType == expectedChecksumType && !strings.EqualFold(actualDigest, expectedChecksum) {
+				log.Printf("skipping image '%s' (extId: %s) - checksum mismatch (expected: %s, actual: %s)",
+					name, *img.ExtId, expectedChecksum, actualDigest)
+				continue
 			}
 		}
 
@@ -385,6 +471,15 @@ func findImageByName(ctx context.Context, v4Client *convergedv4.Client, name str
 	return &nutanixImage{image: img}, nil
 }
 
+// findImageByFilter finds the image matching a source_image_filter using V4 API
+func findImageByFilter(ctx context.Context, v4Client *convergedv4.Client, filter ImageFilter, imageType string) (*nutanixImage, error) {
+	img, err := findImageByFilterHelper(ctx, v4Client, filter, imageType)
+	if err != nil {
+		return nil, err
+	}
+	return &nutanixImage{image: img}, nil
+}
+
 func (d *NutanixDriver) WaitForShutdown(vmUUID string, cancelCh <-chan struct{}) bool {
 	endCh := d.vmEndCh
 
@@ -401,6 +496,8 @@ func (d *NutanixDriver) WaitForShutdown(vmUUID string, cancelCh <-chan struct{})
 }
 
 func (d *NutanixDriver) CreateRequest(ctx context.Context, vmConfig VmConfig, state multistep.StateBag) (*vmmModels.Vm, error) {
+	ui := state.Get("ui").(packer.Ui)
+
 	v4Client, err := d.getV4Client()
 	if err != nil {
 		return nil, fmt.Errorf("error creating V4 client: %s", err.Error())
@@ -443,42 +540,10 @@ func (d *NutanixDriver) CreateRequest(ctx context.Context, vmConfig VmConfig, st
 		}
 	}
 
-	// Build boot order based on CdRom presence and boot_priority.
+	// Build boot order based on CdRom presence, boot_order and boot_priority.
 	// CdRoms are added post-creation but BEFORE power-on, so including CDROM in
 	// boot order during creation is safe when CdRoms will be attached.
-	// Only include CDROM in boot order when CdRom devices exist - some Nutanix
-	// versions return INTERNAL_ERROR when CDROM is in boot order without CdRom devices.
-	var bootOrder []vmmModels.BootDeviceType
-	if hasCdRoms {
-		if vmConfig.BootPriority == NutanixIdentifierBootPriorityCDROM || vmConfig.BootPriority == "" {
-			// ISO installation: boot from CdRom first (default when CdRoms exist)
-			bootOrder = []vmmModels.BootDeviceType{
-				vmmModels.BOOTDEVICETYPE_CDROM,
-				vmmModels.BOOTDEVICETYPE_DISK,
-				vmmModels.BOOTDEVICETYPE_NETWORK,
-			}
-		} else {
-			// User explicitly chose disk boot priority even with CdRoms
-			bootOrder = []vmmModels.BootDeviceType{
-				vmmModels.BOOTDEVICETYPE_DISK,
-				vmmModels.BOOTDEVICETYPE_CDROM,
-				vmmModels.BOOTDEVICETYPE_NETWORK,
-			}
-		}
-	} else if vmConfig.BootPriority == NutanixIdentifierBootPriorityCDROM {
-		// No CdRoms but user explicitly requested cdrom boot priority - use DISK first
-		// since there are no CdRom devices to boot from.
-		log.Printf("WARNING: boot_priority is 'cdrom' but no CdRom devices configured, using DISK boot order")
-		bootOrder = []vmmModels.BootDeviceType{
-			vmmModels.BOOTDEVICETYPE_DISK,
-			vmmModels.BOOTDEVICETYPE_NETWORK,
-		}
-	} else {
-		bootOrder = []vmmModels.BootDeviceType{
-			vmmModels.BOOTDEVICETYPE_DISK,
-			vmmModels.BOOTDEVICETYPE_NETWORK,
-		}
-	}
+	bootOrder := bootOrderFromConfig(vmConfig, hasCdRoms)
 
 	v4vm.BootConfig = vmmModels.NewOneOfVmBootConfig()
 	switch vmConfig.BootType {
@@ -511,15 +576,21 @@ func (d *NutanixDriver) CreateRequest(ctx context.Context, vmConfig VmConfig, st
 	// V4 API does NOT allow PowerState during creation - must use separate power-on call
 	// Error: "Cannot specify power state as ON during VM creation. Please use the VM power action endpoints instead."
 
+	// Images uploaded from source_image_path are marked to delete once, even when disks share
+	// their path, and not when they were reused by content: those belong to other builds
 	var imageToDelete []string
-	SATAindex := 0
-	SCSIindex := 0
+	reused, _ := state.Get("reused_images").([]string)
 
-	for _, disk := range vmConfig.VmDisks {
+	addresses, err := diskAddresses(vmConfig.VmDisks)
+	if err != nil {
+		return nil, fmt.Errorf("error while assigning disk addresses: %s", err.Error())
+	}
+
+	for i, disk := range vmConfig.VmDisks {
 		if disk.ImageType == "DISK_IMAGE" {
 			var image *nutanixImage
 			if disk.SourceImageURI != "" {
-				image, err = d.CreateImageURL(ctx, disk, vmConfig)
+				image, err = d.CreateImageURL(ctx, disk, vmConfig, ui)
 				if err != nil {
 					return nil, fmt.Errorf("error while CreateImageURL, Error %s", err.Error())
 				}
@@ -537,7 +608,7 @@ func (d *NutanixDriver) CreateRequest(ctx context.Context, vmConfig VmConfig, st
 					return nil, fmt.Errorf("error while findImageByUUID, Error %s", err.Error())
 				}
 
-				if disk.SourceImageDelete && disk.SourceImagePath != "" {
+				if disk.SourceImageDelete && disk.SourceImagePath != "" && !slices.Contains(reused, image.UUID()) && !slices.Contains(imageToDelete, image.UUID()) {
 					log.Printf("mark this image to delete %s:", image.Name())
 					imageToDelete = append(imageToDelete, image.UUID())
 				}
@@ -546,14 +617,20 @@ func (d *NutanixDriver) CreateRequest(ctx context.Context, vmConfig VmConfig, st
 				if err != nil {
 					return nil, fmt.Errorf("error while findImageByName, %s", err.Error())
 				}
+			} else if !disk.SourceImageFilter.empty() {
+				image, err = findImageByFilter(ctx, v4Client, disk.SourceImageFilter, disk.ImageType)
+				if err != nil {
+					return nil, fmt.Errorf("error while findImageByFilter, %s", err.Error())
+				}
+				ui.Sayf("Disk %d: source_image_filter selected image %s (%s)", i, image.Name(), image.UUID())
 			}
 
 			v4Disk := vmmModels.NewDisk()
 			v4Disk.DiskAddress = vmmModels.NewDiskAddress()
-			v4Disk.DiskAddress.BusType = vmmModels.DISKBUSTYPE_SCSI.Ref()
+			v4Disk.DiskAddress.BusType = diskBusTypes[addresses[i].busType].Ref()
 			// Create a copy of index to avoid pointer aliasing issue
-			scsiIdx := SCSIindex
-			v4Disk.DiskAddress.Index = &scsiIdx
+			diskIdx := addresses[i].index
+			v4Disk.DiskAddress.Index = &diskIdx
 
 			vmDisk := vmmModels.NewVmDisk()
 			diskSizeBytes := disk.DiskSizeGB * bytesPerGB
@@ -562,6 +639,16 @@ func (d *NutanixDriver) CreateRequest(ctx context.Context, vmConfig VmConfig, st
 			}
 			vmDisk.DiskSizeBytes = &diskSizeBytes
 
+			// The image is cloned into the storage container when one is set
+			if disk.StorageContainerUUID != "" || disk.StorageContainerName != "" {
+				containerUUID, err := getStorageContainerUUID(ctx, v4Client, disk.StorageContainerName, disk.StorageContainerUUID, clusterUUID)
+				if err != nil {
+					return nil, fmt.Errorf("error resolving storage container for vm_disks %d: %s", i+1, err.Error())
+				}
+				vmDisk.StorageContainer = vmmModels.NewVmDiskContainerReference()
+				vmDisk.StorageContainer.ExtId = &containerUUID
+			}
+
 			// Use NewDataSource() for proper initialization, set Reference directly to avoid discriminator
 			imageUUID := image.UUID()
 			imageRef := vmmModels.NewImageReference()
@@ -581,24 +668,27 @@ func (d *NutanixDriver) CreateRequest(ctx context.Context, vmConfig VmConfig, st
 			}
 			v4Disk.BackingInfo = backingInfo
 			v4vm.Disks = append(v4vm.Disks, *v4Disk)
-			SCSIindex++
 		}
 
 		if disk.ImageType == "DISK" {
 			v4Disk := vmmModels.NewDisk()
 			v4Disk.DiskAddress = vmmModels.NewDiskAddress()
-			v4Disk.DiskAddress.BusType = vmmModels.DISKBUSTYPE_SCSI.Ref()
+			v4Disk.DiskAddress.BusType = diskBusTypes[addresses[i].busType].Ref()
 			// Create a copy of index to avoid pointer aliasing issue
-			scsiIdx := SCSIindex
-			v4Disk.DiskAddress.Index = &scsiIdx
+			diskIdx := addresses[i].index
+			v4Disk.DiskAddress.Index = &diskIdx
 
 			vmDisk := vmmModels.NewVmDisk()
 			diskSizeBytes := disk.DiskSizeGB * bytesPerGB
 			vmDisk.DiskSizeBytes = &diskSizeBytes
 
-			if disk.StorageContainerUUID != "" {
+			if disk.StorageContainerUUID != "" || disk.StorageContainerName != "" {
+				containerUUID, err := getStorageContainerUUID(ctx, v4Client, disk.StorageContainerName, disk.StorageContainerUUID, clusterUUID)
+				if err != nil {
+					return nil, fmt.Errorf("error resolving storage container for vm_disks %d: %s", i+1, err.Error())
+				}
 				vmDisk.StorageContainer = vmmModels.NewVmDiskContainerReference()
-				vmDisk.StorageContainer.ExtId = &disk.StorageContainerUUID
+				vmDisk.StorageContainer.ExtId = &containerUUID
 			}
 
 			// Directly assign BackingInfo to avoid $backingInfoItemDiscriminator in JSON
@@ -608,13 +698,12 @@ func (d *NutanixDriver) CreateRequest(ctx context.Context, vmConfig VmConfig, st
 			}
 			v4Disk.BackingInfo = backingInfo
 			v4vm.Disks = append(v4vm.Disks, *v4Disk)
-			SCSIindex++
 		}
 
 		if disk.ImageType == "ISO_IMAGE" {
 			var image *nutanixImage
 			if disk.SourceImageURI != "" {
-				image, err = d.CreateImageURL(ctx, disk, vmConfig)
+				image, err = d.CreateImageURL(ctx, disk, vmConfig, ui)
 				if err != nil {
 					return nil, fmt.Errorf("error while CreateImageURL, Error %s", err.Error())
 				}
@@ -632,7 +721,7 @@ func (d *NutanixDriver) CreateRequest(ctx context.Context, vmConfig VmConfig, st
 					return nil, fmt.Errorf("error while findImageByUUID, %s", err.Error())
 				}
 
-				if disk.SourceImageDelete && disk.SourceImagePath != "" {
+				if disk.SourceImageDelete && disk.SourceImagePath != "" && !slices.Contains(reused, image.UUID()) && !slices.Contains(imageToDelete, image.UUID()) {
 					log.Printf("mark this image to delete %s:", image.Name())
 					imageToDelete = append(imageToDelete, image.UUID())
 				}
@@ -641,32 +730,42 @@ func (d *NutanixDriver) CreateRequest(ctx context.Context, vmConfig VmConfig, st
 				if err != nil {
 					return nil, fmt.Errorf("error while findImageByName, %s", err.Error())
 				}
+			} else if !disk.SourceImageFilter.empty() {
+				image, err = findImageByFilter(ctx, v4Client, disk.SourceImageFilter, disk.ImageType)
+				if err != nil {
+					return nil, fmt.Errorf("error while findImageByFilter, %s", err.Error())
+				}
+				ui.Sayf("Disk %d: source_image_filter selected image %s (%s)", i, image.Name(), image.UUID())
 			}
 
 			v4CdRom := vmmModels.NewCdRom()
-			vmDisk := vmmModels.NewVmDisk()
-			imageUUID := image.UUID()
-			imageRef := vmmModels.NewImageReference()
-			imageRef.ImageExtId = &imageUUID
-			dataSourceRef := vmmModels.NewOneOfDataSourceReference()
-			if err := dataSourceRef.SetValue(*imageRef); err != nil {
-				return nil, fmt.Errorf("error setting data source reference: %s", err.Error())
+
+			// Without a source image the CdRom is created as an empty drive,
+			// ready for media to be inserted later with media_change.
+			if image != nil {
+				vmDisk := vmmModels.NewVmDisk()
+				imageUUID := image.UUID()
+				imageRef := vmmModels.NewImageReference()
+				imageRef.ImageExtId = &imageUUID
+				dataSourceRef := vmmModels.NewOneOfDataSourceReference()
+				if err := dataSourceRef.SetValue(*imageRef); err != nil {
+					return nil, fmt.Errorf("error setting data source reference: %s", err.Error())
+				}
+				dataSource := vmmModels.NewDataSource()
+				dataSource.Reference = dataSourceRef
+				vmDisk.DataSource = dataSource
+				v4CdRom.BackingInfo = vmDisk
 			}
-			dataSource := vmmModels.NewDataSource()
-			dataSource.Reference = dataSourceRef
-			vmDisk.DataSource = dataSource
-			v4CdRom.BackingInfo = vmDisk
 
 			// Set explicit DiskAddress to avoid "disk bus already in use" when
 			// multiple CdRoms are created inline (e.g. ISO + kickstart CD).
 			cdromAddr := vmmModels.NewCdRomAddress()
-			cdromAddr.BusType = vmmModels.CDROMBUSTYPE_SATA.Ref()
+			cdromAddr.BusType = cdromBusTypes[addresses[i].busType].Ref()
 			cdromAddr.Index = new(int)
-			*cdromAddr.Index = SATAindex
+			*cdromAddr.Index = addresses[i].index
 			v4CdRom.DiskAddress = cdromAddr
 
 			v4vm.CdRoms = append(v4vm.CdRoms, *v4CdRom)
-			SATAindex++
 		}
 	}
 
@@ -746,38 +845,9 @@ func (d *NutanixDriver) CreateRequest(ctx context.Context, vmConfig VmConfig, st
 
 	if vmConfig.UserData != "" {
 		log.Printf("Setting up GuestCustomization for OS type: %s", vmConfig.OSType)
-		v4vm.GuestCustomization = vmmModels.NewGuestCustomizationParams()
-
-		if vmConfig.OSType == "Linux" {
-			cloudInit := vmmModels.NewCloudInit()
-			userDataScript := vmmModels.NewUserdata()
-			userDataScript.Value = &vmConfig.UserData
-			cloudInit.CloudInitScript = vmmModels.NewOneOfCloudInitCloudInitScript()
-			if err := cloudInit.CloudInitScript.SetValue(*userDataScript); err != nil {
-				return nil, fmt.Errorf("error setting cloud-init script: %s", err.Error())
-			}
-			// Directly assign Config to avoid $configItemDiscriminator in JSON
-			guestConfig := vmmModels.NewOneOfGuestCustomizationParamsConfig()
-			if err := guestConfig.SetValue(*cloudInit); err != nil {
-				return nil, fmt.Errorf("error setting guest customization config: %s", err.Error())
-			}
-			v4vm.GuestCustomization.Config = guestConfig
-			log.Printf("CloudInit configured for Linux VM")
-		} else if vmConfig.OSType == "Windows" {
-			sysprep := vmmModels.NewSysprep()
-			unattendXml := vmmModels.NewUnattendxml()
-			unattendXml.Value = &vmConfig.UserData
-			sysprep.SysprepScript = vmmModels.NewOneOfSysprepSysprepScript()
-			if err := sysprep.SysprepScript.SetValue(*unattendXml); err != nil {
-				return nil, fmt.Errorf("error setting sysprep script: %s", err.Error())
-			}
-			// Directly assign Config to avoid $configItemDiscriminator in JSON
-			guestConfig := vmmModels.NewOneOfGuestCustomizationParamsConfig()
-			if err := guestConfig.SetValue(*sysprep); err != nil {
-				return nil, fmt.Errorf("error setting guest customization config: %s", err.Error())
-			}
-			v4vm.GuestCustomization.Config = guestConfig
-			log.Printf("Sysprep configured for Windows VM")
+		v4vm.GuestCustomization, err = guestCustomizationFromUserData(vmConfig.OSType, vmConfig.UserData)
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -961,7 +1031,7 @@ func (d *NutanixDriver) Delete(ctx context.Context, vmUUID string) error {
 }
 
 // CreateImageURL (VmDisk, VmConfig) (*nutanixImage, error)
-func (d *NutanixDriver) CreateImageURL(ctx context.Context, disk VmDisk, vm VmConfig) (*nutanixImage, error) {
+func (d *NutanixDriver) CreateImageURL(ctx context.Context, disk VmDisk, vm VmConfig, ui packer.Ui) (*nutanixImage, error) {
 	v4Client, err := d.getV4Client()
 	if err != nil {
 		return nil, fmt.Errorf("error creating V4 client: %s", err.Error())
@@ -969,12 +1039,17 @@ func (d *NutanixDriver) CreateImageURL(ctx context.Context, disk VmDisk, vm VmCo
 
 	_, file := path.Split(disk.SourceImageURI)
 
+	checksumType, checksum, err := resolveChecksum(ctx, disk.SourceImageChecksum, disk.SourceImageChecksumType, file)
+	if err != nil {
+		return nil, err
+	}
+
 	clusterUUID, err := getClusterUUID(ctx, v4Client, vm.ClusterName, vm.ClusterUUID)
 	if err != nil {
 		return nil, fmt.Errorf("error while getting cluster: %s", err.Error())
 	}
 
-	existingImage, err := sourceImageExists(ctx, v4Client, file, disk.SourceImageURI, disk.SourceImageChecksum, d.Config.AllowDuplicateImages)
+	existingImage, err := sourceImageExists(ctx, v4Client, file, disk.SourceImageURI, checksumType, checksum, d.Config.AllowDuplicateImages)
 	if err != nil {
 		return nil, fmt.Errorf("error while checking if image exists, %s", err.Error())
 	}
@@ -1009,70 +1084,195 @@ func (d *NutanixDriver) CreateImageURL(ctx context.Context, disk VmDisk, vm VmCo
 		return nil, fmt.Errorf("error setting image source: %s", err.Error())
 	}
 
-	if disk.SourceImageChecksum != "" {
-		v4Image.Checksum = imageModels.NewOneOfImageChecksum()
-		switch disk.SourceImageChecksumType {
-		case NutanixIdentifierChecksunTypeSHA256:
-			sha256Checksum := imageModels.NewImageSha256Checksum()
-			sha256Checksum.HexDigest = &disk.SourceImageChecksum
-			if err := v4Image.Checksum.SetValue(*sha256Checksum); err != nil {
-				return nil, fmt.Errorf("error setting SHA256 checksum: %s", err.Error())
-			}
-			log.Printf("image checksum (SHA256): %s", disk.SourceImageChecksum)
-		case NutanixIdentifierChecksunTypeSHA1:
-			sha1Checksum := imageModels.NewImageSha1Checksum()
-			sha1Checksum.HexDigest = &disk.SourceImageChecksum
-			if err := v4Image.Checksum.SetValue(*sha1Checksum); err != nil {
-				return nil, fmt.Errorf("error setting SHA1 checksum: %s", err.Error())
-			}
-			log.Printf("image checksum (SHA1): %s", disk.SourceImageChecksum)
+	// Prism verifies SHA-1 and SHA-256 checksums itself, other types are checked once the image is created
+	switch checksumType {
+	case NutanixIdentifierChecksunTypeSHA1, NutanixIdentifierChecksunTypeSHA256:
+		v4Image.Checksum, err = newImageChecksum(checksumType, checksum)
+		if err != nil {
+			return nil, err
 		}
+		log.Printf("image checksum (%s): %s", checksumType, checksum)
 	}
 
 	v4Image.ClusterLocationExtIds = []string{clusterUUID}
 
 	log.Printf("Creating image - Name: %s, Type: %s, Cluster: %s", *v4Image.Name, v4Image.Type.GetName(), clusterUUID)
 
-	createdImage, err := v4Client.Images.Create(ctx, v4Image)
+	// The download, from the image creation to the image being ready, is bound by source_image_download_timeout
+	timeout := d.Config.SourceImageDownloadTimeout
+	downloadCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	sdkClient, err := d.getV4SDKClient()
+	if err != nil {
+		return nil, fmt.Errorf("error creating V4 client: %s", err.Error())
+	}
+
+	taskRef, err := convergedv4.CallAPI[*imageModels.CreateImageApiResponse, vmmPrismModels.TaskReference](
+		sdkClient.ImagesApiInstance.CreateImage(v4Image),
+	)
 	if err != nil {
 		log.Printf("ERROR: Image creation failed: %s", err.Error())
 		log.Printf("Full error details: %+v", err)
 		return nil, fmt.Errorf("error while creating image: %s", err.Error())
 	}
+	if taskRef.ExtId == nil {
+		return nil, fmt.Errorf("error while creating image: task reference has no ExtId")
+	}
+
+	operation := convergedv4.NewOperation(*taskRef.ExtId, sdkClient, v4Client.Images.Get)
+	stopProgress := reportTaskProgress(downloadCtx, sdkClient, ui, "Downloading "+file, operation.UUID())
+	createdImages, err := operation.Wait(downloadCtx)
+	stopProgress()
+	if err != nil {
+		if downloadCtx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("image %s was not downloaded within source_image_download_timeout (%s), raise it for big images", file, timeout)
+		}
+		log.Printf("ERROR: Image creation failed: %s", err.Error())
+		return nil, fmt.Errorf("error while creating image: %s", err.Error())
+	}
+	if len(createdImages) == 0 || createdImages[0].ExtId == nil {
+		return nil, fmt.Errorf("error while creating image: task %s returned no image", operation.UUID())
+	}
 
 	log.Printf("image successfully created")
 
 	// Verify image is fully ready before returning
 	// The V4 API task may complete before the image is fully usable for VM disk cloning
 	// Using 5-second intervals to match V3 API's checkTask polling behavior
-	imageUUID := *createdImage.ExtId
+	imageUUID := *createdImages[0].ExtId
 	log.Printf("Verifying image %s is ready for use...", imageUUID)
 
-	maxRetries := 12 // 12 retries * 5 seconds = 60 seconds max wait
-	for i := 0; i < maxRetries; i++ {
-		verifiedImage, verifyErr := v4Client.Images.Get(ctx, imageUUID)
+	for {
+		verifiedImage, verifyErr := v4Client.Images.Get(downloadCtx, imageUUID)
 		if verifyErr != nil {
-			log.Printf("Error verifying image (attempt %d/%d): %s", i+1, maxRetries, verifyErr.Error())
-			time.Sleep(5 * time.Second)
-			continue
-		}
-
-		// Check if SizeBytes is set - indicates image data is available
-		if verifiedImage.SizeBytes != nil && *verifiedImage.SizeBytes > 0 {
+			log.Printf("Error verifying image %s: %s", imageUUID, verifyErr.Error())
+		} else if verifiedImage.SizeBytes != nil && *verifiedImage.SizeBytes > 0 {
+			// Check if SizeBytes is set - indicates image data is available
 			log.Printf("Image %s is ready (size: %d bytes)", imageUUID, *verifiedImage.SizeBytes)
+			if err := d.verifyImageContent(ctx, ui, imageUUID, *verifiedImage.SizeBytes, checksumType, checksum); err != nil {
+				return nil, err
+			}
 			return &nutanixImage{image: verifiedImage}, nil
+		} else {
+			log.Printf("Image %s not ready yet (SizeBytes is nil or 0), waiting...", imageUUID)
 		}
 
-		log.Printf("Image %s not ready yet (SizeBytes is nil or 0), waiting... (attempt %d/%d)", imageUUID, i+1, maxRetries)
-		time.Sleep(5 * time.Second)
+		select {
+		case <-downloadCtx.Done():
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			// A never ready image would be skipped by the next builds, it is removed
+			if err := d.DeleteImage(context.WithoutCancel(ctx), imageUUID); err != nil {
+				log.Printf("failed to delete image %s: %s", imageUUID, err.Error())
+			}
+			return nil, fmt.Errorf("image %s (%s) is still not ready after source_image_download_timeout (%s)", file, imageUUID, timeout)
+		case <-time.After(5 * time.Second):
+		}
 	}
+}
 
-	log.Printf("WARNING: Image %s readiness check timed out, proceeding anyway...", imageUUID)
-	return &nutanixImage{image: createdImage}, nil
+// reportTaskProgress shows the progress of the V4 task taskUUID as a progress bar until the
+// returned function is called.
+func reportTaskProgress(ctx context.Context, sdkClient *v4.Client, ui packer.Ui, name string, taskUUID string) func() {
+	progress := newTransferProgress(ui, name, 100)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		reported := 0
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case <-time.After(5 * time.Second):
+			}
+
+			percent, err := taskProgress(sdkClient, taskUUID)
+			if err != nil {
+				log.Printf("failed to get progress of task %s: %s", taskUUID, err.Error())
+				continue
+			}
+			if percent > reported {
+				log.Printf("task %s: %d%%", taskUUID, percent)
+				progress.add(int64(percent - reported))
+				reported = percent
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+		progress.Close()
+	}
+}
+
+// verifyImageContent downloads the image imageUUID of size bytes to check a checksum Prism can't
+// verify (SHA-512, MD5), which transfers the whole image a second time. The image is deleted when
+// its checksum doesn't match.
+func (d *NutanixDriver) verifyImageContent(ctx context.Context, ui packer.Ui, imageUUID string, size int64, checksumType string, checksum string) error {
+	if checksumType != NutanixIdentifierChecksunTypeSHA512 && checksumType != NutanixIdentifierChecksunTypeMD5 {
+		return nil
+	}
+
+	ui.Sayf("Downloading image %s back to verify its %s checksum...", imageUUID, checksumType)
+	h, err := newChecksumHash(checksumType)
+	if err != nil {
+		return err
+	}
+
+	body, _, err := d.ExportImage(ctx, imageUUID, 0)
+	if err != nil {
+		return fmt.Errorf("error downloading image to verify its checksum: %s", err.Error())
+	}
+	progress := newTransferProgress(ui, imageUUID, size)
+	reader := progress.reader(&contextReader{ctx: ctx, ReadCloser: body})
+	_, err = io.Copy(h, reader)
+	reader.Close()
+	progress.Close()
+	if err != nil {
+		return fmt.Errorf("error downloading image to verify its checksum: %s", err.Error())
+	}
+
+	if actual := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(actual, checksum) {
+		if err := d.DeleteImage(ctx, imageUUID); err != nil {
+			log.Printf("failed to delete corrupted image %s: %s", imageUUID, err.Error())
+		}
+		return fmt.Errorf("image %s checksum mismatch: expected %s, got %s", checksumType, checksum, actual)
+	}
+	return nil
 }
 
-// CreateImageFile uploads a local file as a new image using Objects Lite.
-func (d *NutanixDriver) CreateImageFile(ctx context.Context, filePath string, vm VmConfig) (*nutanixImage, error) {
+const (
+	// objectsLiteEndpoint is the S3 endpoint of the Objects Lite storage of Prism Central, from
+	// its host and port
+	objectsLiteEndpoint = "https://%s:%d/api/prism/v4.0/objects/"
+	// objectsLiteBucket is the Objects Lite bucket images are imported from
+	objectsLiteBucket = "vmm-images"
+	// objectsLitePartSize is the size of the parts of image uploads, the unit of resumption
+	objectsLitePartSize = 16 * 1024 * 1024
+	// objectsLiteKeyPrefix prefixes the keys of the images uploaded by the plugin, the bucket is
+	// shared with other uploaders and only the uploads under the prefix are resumed or aborted
+	objectsLiteKeyPrefix = "packer"
+	// objectsLiteIdleUploadAge is the time without activity after which an unfinished image
+	// upload is no longer being written by another build and can be resumed
+	objectsLiteIdleUploadAge = 10 * time.Minute
+	// objectsLiteStaleUploadAge is the time without activity after which an unfinished image
+	// upload is aborted
+	objectsLiteStaleUploadAge = 24 * time.Hour
+)
+
+// CreateImageFile uploads a local file as a new image using Objects Lite. The file is uploaded in
+// parts with progress in the Packer UI, failed uploads are resumed, and the image checksum
+// reported by Prism is checked against the SHA-256 of the file, and the file against checksum when
+// set. The SHA-256 is kept in the image
+// description, and an existing ready image with the same content is reused instead of uploaded
+// again, whatever its name.
+func (d *NutanixDriver) CreateImageFile(ctx context.Context, filePath string, checksum string, checksumType string, vm VmConfig, ui packer.Ui) (*nutanixImage, error) {
 	v4Client, err := d.getV4TransferClient()
 	if err != nil {
 		return nil, fmt.Errorf("error creating V4 client: %s", err.Error())
@@ -1080,21 +1280,102 @@ func (d *NutanixDriver) CreateImageFile(ctx context.Context, filePath string, vm
 
 	_, file := filepath.Split(filePath)
 
-	log.Printf("creating and uploading image: %s", file)
+	fi, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading image file: %s", err.Error())
+	}
+
+	clusterUUID, err := getClusterUUID(ctx, v4Client, vm.ClusterName, vm.ClusterUUID)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting cluster: %s", err.Error())
+	}
+
+	expectedType, expected, err := resolveChecksum(ctx, checksum, checksumType, file)
+	if err != nil {
+		return nil, err
+	}
+
+	checksumTypes := []string{NutanixIdentifierChecksunTypeSHA256}
+	if expected != "" {
+		checksumTypes = append(checksumTypes, expectedType)
+	}
+
+	log.Printf("computing checksums of %s", filePath)
+	sums, err := fileChecksums(filePath, checksumTypes...)
+	if err != nil {
+		return nil, fmt.Errorf("error computing image checksum: %s", err.Error())
+	}
 
-	err = v4Client.Images.Upload(ctx, file, filePath)
+	if expected != "" && !strings.EqualFold(sums[expectedType], expected) {
+		return nil, fmt.Errorf("%s checksum mismatch: expected %s, got %s", expectedType, expected, sums[expectedType])
+	}
+	checksum = sums[NutanixIdentifierChecksunTypeSHA256]
+
+	existingImage, err := findUploadedImage(ctx, v4Client, file, checksum)
+	if err != nil {
+		return nil, fmt.Errorf("error while checking if image exists: %s", err.Error())
+	}
+	if existingImage != nil {
+		log.Printf("reuse existing image %s with the same SHA256 %s", *existingImage.ExtId, checksum)
+		return &nutanixImage{image: existingImage, reused: true}, nil
+	}
+
+	log.Printf("uploading image: %s", file)
+
+	// Objects are keyed by content, so an interrupted upload is resumed whatever the file name
+	destination := newObjectsLiteDestination(d.ClusterConfig)
+	name := strings.ToLower(checksum)
+	key := destination.key(name)
+	progress := newTransferProgress(ui, file, fi.Size())
+	err = destination.uploadFile(ctx, name, filePath, d.Config.UploadRetries, progress)
+	progress.Close()
 	if err != nil {
 		return nil, fmt.Errorf("error while uploading image: %s", err.Error())
 	}
 
-	createdImage, err := findImageByName(ctx, v4Client, file, d.Config.AllowDuplicateImages)
+	log.Printf("creating image %s from the uploaded file", file)
+
+	objectsSource := imageModels.NewObjectsLiteSource()
+	objectsSource.Key = &key
+
+	v4Image := imageModels.NewImage()
+	v4Image.Name = &file
+	v4Image.Description = StringPtr(uploadedImageDescription(checksum))
+	v4Image.Type = imageModels.IMAGETYPE_DISK_IMAGE.Ref()
+	if strings.EqualFold(filepath.Ext(file), ".iso") {
+		v4Image.Type = imageModels.IMAGETYPE_ISO_IMAGE.Ref()
+	}
+
+	v4Image.Source = imageModels.NewOneOfImageSource()
+	if err := v4Image.Source.SetValue(*objectsSource); err != nil {
+		return nil, fmt.Errorf("error setting image source: %s", err.Error())
+	}
+
+	v4Image.Checksum, err = newImageChecksum(NutanixIdentifierChecksunTypeSHA256, checksum)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prism stores the image itself, the storage container of the disk applies when the image is
+	// cloned into the VM disk
+	v4Image.ClusterLocationExtIds = []string{clusterUUID}
+
+	createdImage, err := v4Client.Images.Create(ctx, v4Image)
 	if err != nil {
-		return nil, fmt.Errorf("error while getting created image: %s", err.Error())
+		return nil, fmt.Errorf("error while creating image: %s", err.Error())
+	}
+
+	// Prism checks the checksum given at creation, a different one means corrupted content
+	if _, actual := imageChecksum(createdImage); actual != "" && !strings.EqualFold(actual, checksum) {
+		if err := d.DeleteImage(ctx, *createdImage.ExtId); err != nil {
+			log.Printf("failed to delete corrupted image %s: %s", *createdImage.ExtId, err.Error())
+		}
+		return nil, fmt.Errorf("image checksum mismatch: expected %s, got %s", checksum, actual)
 	}
 
 	log.Printf("image successfully uploaded: %s", file)
 
-	return createdImage, nil
+	return &nutanixImage{image: createdImage}, nil
 }
 
 func (d *NutanixDriver) DeleteImage(ctx context.Context, imageUUID string) error {
@@ -1137,187 +1418,700 @@ func (d *NutanixDriver) GetVM(ctx context.Context, vmUUID string) (*nutanixInsta
 	return &nutanixInstance{vm: vm}, nil
 }
 
-// findOvaByName finds the latest OVA by name using V4 API and returns its UUID
-func findOvaByName(ctx context.Context, v4Client *convergedv4.Client, name string) (string, error) {
+// findOvasByName finds all the OVAs with the given name using V4 API and returns their UUIDs
+func findOvasByName(ctx context.Context, v4Client *convergedv4.Client, name string) ([]string, error) {
 	ovas, err := v4Client.Ovas.List(ctx, converged.WithFilter(fmt.Sprintf("name eq '%s'", name)))
 	if err != nil {
-		return "", err
-	}
-
-	if len(ovas) == 0 {
-		return "", nil
+		return nil, err
 	}
 
 	// Filter by exact name match
-	found := make([]imageModels.Ova, 0)
+	var found []string
 	for _, ova := range ovas {
-		if ova.Name != nil && strings.EqualFold(*ova.Name, name) {
-			found = append(found, ova)
-		}
-	}
-
-	if len(found) == 0 {
-		return "", nil
-	}
-
-	// Sort by CreateTime to get latest (descending order)
-	sort.Slice(found, func(i, j int) bool {
-		if found[i].CreateTime == nil || found[j].CreateTime == nil {
-			return found[i].CreateTime != nil
+		if ova.Name != nil && strings.EqualFold(*ova.Name, name) && ova.ExtId != nil {
+			found = append(found, *ova.ExtId)
 		}
-		return found[i].CreateTime.After(*found[j].CreateTime)
-	})
-
-	if found[0].ExtId == nil {
-		return "", fmt.Errorf("OVA %s has no ExtId", name)
 	}
-	return *found[0].ExtId, nil
+	return found, nil
 }
 
-func (d *NutanixDriver) CreateTemplate(ctx context.Context, vmUUID string, templateConfig TemplateConfig) error {
+func (d *NutanixDriver) CreateTemplate(ctx context.Context, vmUUID string, templateConfig TemplateConfig) (*nutanixTemplate, error) {
 	v4Client, err := d.getV4Client()
 	if err != nil {
-		return fmt.Errorf("error creating V4 client: %s", err.Error())
+		return nil, fmt.Errorf("error creating V4 client: %s", err.Error())
 	}
 
-	log.Printf("creating template %s from VM %s", templateConfig.Name, vmUUID)
-
 	vmRef := imageModels.NewTemplateVmReference()
 	vmRef.ExtId = &vmUUID
 
 	versionSpec := imageModels.NewTemplateVersionSpec()
-	isActive := true
-	isGcOverride := true
+	isActive := templateConfig.activeVersion()
+	isGcOverride := templateConfig.GuestCustomization.overridable()
 	versionSpec.IsActiveVersion = &isActive
 	versionSpec.IsGcOverrideEnabled = &isGcOverride
+	if templateConfig.VersionName != "" {
+		versionSpec.VersionName = &templateConfig.VersionName
+	}
+	if templateConfig.VersionDescription != "" {
+		versionSpec.VersionDescription = &templateConfig.VersionDescription
+	}
 	if err := versionSpec.SetVersionSource(*vmRef); err != nil {
-		return fmt.Errorf("error setting template version source: %s", err.Error())
+		return nil, fmt.Errorf("error setting template version source: %s", err.Error())
+	}
+
+	versionSpec.VmSpec, err = d.templateVmSpec(ctx, v4Client, templateConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var categoryExtIds []string
+	if len(templateConfig.Categories) != 0 {
+		categoryExtIds, err = getCategoryExtIds(ctx, v4Client, templateConfig.Categories)
+		if err != nil {
+			return nil, fmt.Errorf("error getting category ExtIds: %s", err.Error())
+		}
+	}
+
+	if templateConfig.UpdateExisting {
+		existing, err := findTemplateByName(ctx, v4Client, templateConfig.Name)
+		if err != nil {
+			return nil, fmt.Errorf("error while finding template %s: %s", templateConfig.Name, err.Error())
+		}
+		if existing != nil {
+			return d.addTemplateVersion(ctx, existing, versionSpec, categoryExtIds, templateConfig)
+		}
+		log.Printf("template %s not found, creating it", templateConfig.Name)
 	}
 
+	// The first version of a template is always active
+	isActive = true
+
+	log.Printf("creating template %s from VM %s", templateConfig.Name, vmUUID)
+
 	template := imageModels.NewTemplate()
 	template.TemplateName = &templateConfig.Name
 	template.TemplateDescription = &templateConfig.Description
 	template.TemplateVersionSpec = versionSpec
+	template.CategoryExtIds = categoryExtIds
 
-	_, err = v4Client.Templates.Create(ctx, template)
+	createdTemplate, err := v4Client.Templates.Create(ctx, template)
 	if err != nil {
-		return fmt.Errorf("error creating template: %s", err.Error())
+		return nil, fmt.Errorf("error creating template: %s", err.Error())
 	}
 
 	log.Printf("Template %s created successfully", templateConfig.Name)
-	return nil
+	return &nutanixTemplate{template: createdTemplate, version: createdTemplate.TemplateVersionSpec}, nil
 }
 
-func (d *NutanixDriver) CreateOVA(ctx context.Context, ovaName string, vmUUID string, diskFileFormat string) error {
-	v4Client, err := d.getV4Client()
-	if err != nil {
-		return fmt.Errorf("error creating V4 client: %s", err.Error())
+// templateVmSpec returns the VM configuration applied to the template version: guest
+// customization, project and owner. It returns nil when none of them is configured.
+func (d *NutanixDriver) templateVmSpec(ctx context.Context, v4Client *convergedv4.Client, templateConfig TemplateConfig) (*vmmModels.Vm, error) {
+	if templateConfig.GuestCustomization.UserData == "" && templateConfig.Project == "" && templateConfig.Owner == "" {
+		return nil, nil
 	}
 
-	log.Printf("creating OVA %s from VM %s with disk format %s", ovaName, vmUUID, diskFileFormat)
-
-	vmSource := imageModels.NewOvaVmSource()
-	vmSource.VmExtId = &vmUUID
+	vmSpec := vmmModels.NewVm()
 
-	switch strings.ToUpper(diskFileFormat) {
-	case "QCOW2":
-		vmSource.DiskFileFormat = imageModels.OVADISKFORMAT_QCOW2.Ref()
-	case "VMDK":
-		vmSource.DiskFileFormat = imageModels.OVADISKFORMAT_VMDK.Ref()
-	default:
-		vmSource.DiskFileFormat = imageModels.OVADISKFORMAT_QCOW2.Ref()
+	if templateConfig.GuestCustomization.UserData != "" {
+		guestCustomization, err := guestCustomizationFromUserData(d.Config.OSType, templateConfig.GuestCustomization.UserData)
+		if err != nil {
+			return nil, err
+		}
+		vmSpec.GuestCustomization = guestCustomization
 	}
 
-	ova := imageModels.NewOva()
-	ova.Name = &ovaName
-	if err := ova.SetSource(*vmSource); err != nil {
-		return fmt.Errorf("error setting OVA source: %s", err.Error())
-	}
+	// Project lookup still uses V3 API
+	if templateConfig.Project != "" {
+		conn, err := v3.NewV3Client(d.getConfigCreds())
+		if err != nil {
+			return nil, err
+		}
+		project, err := findProjectByName(ctx, conn, templateConfig.Project)
+		if err != nil {
+			return nil, fmt.Errorf("error while findProjectByName, %s", err.Error())
+		}
+		if project.Metadata != nil && project.Metadata.UUID != nil {
+			vmSpec.Project = vmmModels.NewProjectReference()
+			vmSpec.Project.ExtId = project.Metadata.UUID
+		}
+	}
+
+	if templateConfig.Owner != "" {
+		ownerUUID, err := findUserByName(ctx, v4Client, templateConfig.Owner)
+		if err != nil {
+			return nil, fmt.Errorf("error while finding template owner: %s", err.Error())
+		}
+		vmSpec.OwnershipInfo = vmmModels.NewOwnershipInfo()
+		vmSpec.OwnershipInfo.Owner = vmmModels.NewOwnerReference()
+		vmSpec.OwnershipInfo.Owner.ExtId = &ownerUUID
+	}
+
+	return vmSpec, nil
+}
 
-	_, err = v4Client.Ovas.Create(ctx, ova)
+// addTemplateVersion publishes a new version of an existing template, then deletes the versions
+// beyond the retention count.
+func (d *NutanixDriver) addTemplateVersion(ctx context.Context, template *imageModels.Template, versionSpec *imageModels.TemplateVersionSpec, categoryExtIds []string, templateConfig TemplateConfig) (*nutanixTemplate, error) {
+	sdkClient, err := d.getV4SDKClient()
 	if err != nil {
-		return fmt.Errorf("error creating OVA: %s", err.Error())
+		return nil, fmt.Errorf("error creating V4 SDK client: %s", err.Error())
 	}
 
-	log.Printf("OVA %s created successfully", ovaName)
-	return nil
+	templateUUID := *template.ExtId
+	log.Printf("adding version to template %s (%s)", templateConfig.Name, templateUUID)
+
+	existingTemplate, args, err := convergedv4.GetEntityAndEtag(sdkClient.TemplatesApiInstance.GetTemplateById(&templateUUID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template %s: %s", templateUUID, err.Error())
+	}
+	previousVersions, err := listTemplateVersions(sdkClient, templateUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	update, ok := existingTemplate.GetData().(imageModels.Template)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response for template %s", templateUUID)
+	}
+	update.TemplateVersionSpec = versionSpec
+	if templateConfig.Description != "" {
+		update.TemplateDescription = &templateConfig.Description
+	}
+	if len(categoryExtIds) != 0 {
+		update.CategoryExtIds = categoryExtIds
+	}
+
+	taskRef, err := convergedv4.CallAPI[*imageModels.UpdateTemplateApiResponse, vmmPrismModels.TaskReference](
+		sdkClient.TemplatesApiInstance.UpdateTemplateById(&templateUUID, &update, args),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error adding template version: %s", err.Error())
+	}
+	if err := waitForTask(ctx, sdkClient, taskRef); err != nil {
+		return nil, fmt.Errorf("error waiting for template version: %s", err.Error())
+	}
+
+	// The new version is the one that was not listed before the update
+	versions, err := listTemplateVersions(sdkClient, templateUUID)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(previousVersions))
+	for _, version := range previousVersions {
+		if version.ExtId != nil {
+			known[*version.ExtId] = true
+		}
+	}
+	var newVersion *imageModels.TemplateVersionSpec
+	for i := range versions {
+		if versions[i].ExtId != nil && !known[*versions[i].ExtId] {
+			newVersion = &versions[i]
+			break
+		}
+	}
+	if newVersion == nil {
+		return nil, fmt.Errorf("new version of template %s not found", templateUUID)
+	}
+	log.Printf("version %s added to template %s", *newVersion.ExtId, templateConfig.Name)
+
+	if templateConfig.Retention > 0 {
+		if err := pruneTemplateVersions(ctx, sdkClient, templateUUID, versions, templateConfig.Retention); err != nil {
+			return nil, err
+		}
+	}
+
+	v4Client, err := d.getV4Client()
+	if err != nil {
+		return nil, fmt.Errorf("error creating V4 client: %s", err.Error())
+	}
+	updatedTemplate, err := findTemplateByUUID(ctx, v4Client, templateUUID)
+	if err != nil {
+		return nil, err
+	}
+	return &nutanixTemplate{template: updatedTemplate, version: newVersion}, nil
 }
 
-func (d *NutanixDriver) ExportOVA(ctx context.Context, ovaName string) (string, error) {
-	log.Printf("starting OVA export for OVA: %s", ovaName)
+// DeployTemplate deploys the build VM from the source template version. Name, sizing, NICs and
+// guest customization of the VM request are applied as deployment overrides.
+func (d *NutanixDriver) DeployTemplate(ctx context.Context, v4vm *vmmModels.Vm) (*nutanixInstance, *nutanixTemplate, error) {
+	v4Client, err := d.getV4Client()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating V4 client: %s", err.Error())
+	}
+
+	sdkClient, err := d.getV4SDKClient()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating V4 SDK client: %s", err.Error())
+	}
+
+	vmConfig := d.Config.VmConfig
+
+	var template *imageModels.Template
+	if vmConfig.SourceTemplateUUID != "" {
+		template, err = findTemplateByUUID(ctx, v4Client, vmConfig.SourceTemplateUUID)
+	} else {
+		template, err = findTemplateByName(ctx, v4Client, vmConfig.SourceTemplateName)
+		if err == nil && template == nil {
+			err = fmt.Errorf("template %s not found", vmConfig.SourceTemplateName)
+		}
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while finding source template: %s", err.Error())
+	}
+
+	version, err := findTemplateVersion(sdkClient, template, vmConfig.SourceTemplateVersion)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while finding source template version: %s", err.Error())
+	}
+	source := &nutanixTemplate{template: template, version: version}
+
+	log.Printf("deploying vm %s from template %s version %s...", d.Config.VMName, source.Name(), source.VersionUUID())
+
+	override := imageModels.NewVmConfigOverride()
+	override.Name = v4vm.Name
+	if vmConfig.CPU > 0 {
+		override.NumSockets = v4vm.NumSockets
+	}
+	if vmConfig.Core > 0 {
+		override.NumCoresPerSocket = v4vm.NumCoresPerSocket
+	}
+	if vmConfig.MemoryMB > 0 {
+		override.MemorySizeBytes = v4vm.MemorySizeBytes
+	}
+	override.Nics = v4vm.Nics
+	override.GuestCustomization = v4vm.GuestCustomization
+
+	numberOfVms := 1
+	deployment := imageModels.NewTemplateDeployment()
+	deployment.ClusterReference = v4vm.Cluster.ExtId
+	deployment.NumberOfVms = &numberOfVms
+	deployment.VersionId = version.ExtId
+	deployment.OverrideVmConfigMap = map[string]imageModels.VmConfigOverride{"0": *override}
+
+	_, args, err := convergedv4.GetEntityAndEtag(sdkClient.TemplatesApiInstance.GetTemplateById(template.ExtId))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get template %s: %s", source.UUID(), err.Error())
+	}
+
+	taskRef, err := convergedv4.CallAPI[*imageModels.DeployTemplateApiResponse, vmmPrismModels.TaskReference](
+		sdkClient.TemplatesApiInstance.DeployTemplate(template.ExtId, deployment, args),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error deploying template: %s", err.Error())
+	}
+
+	vmUUIDs, err := waitForTaskEntities(ctx, sdkClient, taskRef, "vm")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error waiting for template deployment: %s", err.Error())
+	}
+	if len(vmUUIDs) == 0 {
+		return nil, nil, fmt.Errorf("template deployment completed but no VM returned")
+	}
+	vmUUID := vmUUIDs[0]
 
+	v4VMResult, err := v4Client.VMs.Get(ctx, vmUUID)
+	if err != nil {
+		log.Printf("error getting vm after deployment: %s", err.Error())
+		return nil, nil, err
+	}
+
+	log.Printf("vm %s deployed successfully (powered off)", vmUUID)
+	return &nutanixInstance{vm: v4VMResult}, source, nil
+}
+
+// CloneVM clones the build VM from the source VM, or restores it from a recovery point of the
+// source VM. Sizing, NICs, guest customization and categories of the VM request override the
+// source configuration.
+func (d *NutanixDriver) CloneVM(ctx context.Context, v4vm *vmmModels.Vm) (*nutanixInstance, error) {
 	v4Client, err := d.getV4Client()
 	if err != nil {
-		return "", fmt.Errorf("error creating V4 client: %s", err.Error())
+		return nil, fmt.Errorf("error creating V4 client: %s", err.Error())
+	}
+
+	sdkClient, err := d.getV4SDKClient()
+	if err != nil {
+		return nil, fmt.Errorf("error creating V4 SDK client: %s", err.Error())
 	}
 
-	var ovaUUID string
+	vmConfig := d.Config.VmConfig
 
-	// Wait for OVA to appear in list (up to 60s)
-	for i := 0; i < 12; i++ {
-		ovaUUID, err = findOvaByName(ctx, v4Client, ovaName)
+	sourceUUID := vmConfig.SourceVMUUID
+	if sourceUUID == "" {
+		sourceVM, err := findVMByName(ctx, v4Client, vmConfig.SourceVMName)
 		if err != nil {
-			log.Printf("error finding OVA: %s", err.Error())
+			return nil, fmt.Errorf("error while finding source VM: %s", err.Error())
 		}
-		if ovaUUID == "" {
-			<-time.After(5 * time.Second)
-		} else {
+		if sourceVM == nil || sourceVM.ExtId == nil {
+			return nil, fmt.Errorf("source VM %s not found", vmConfig.SourceVMName)
+		}
+		sourceUUID = *sourceVM.ExtId
+	}
+
+	var vmUUIDs []string
+	if vmConfig.SourceRecoveryPoint == "" {
+		log.Printf("cloning vm %s from vm %s...", d.Config.VMName, sourceUUID)
+
+		params := vmmModels.NewCloneOverrideParams()
+		params.Name = v4vm.Name
+		if vmConfig.CPU > 0 {
+			params.NumSockets = v4vm.NumSockets
+		}
+		if vmConfig.Core > 0 {
+			params.NumCoresPerSocket = v4vm.NumCoresPerSocket
+		}
+		if vmConfig.MemoryMB > 0 {
+			params.MemorySizeBytes = v4vm.MemorySizeBytes
+		}
+		params.Nics = v4vm.Nics
+		params.GuestCustomization = v4vm.GuestCustomization
+
+		_, args, err := convergedv4.GetEntityAndEtag(sdkClient.VmApiInstance.GetVmById(&sourceUUID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get source VM %s: %s", sourceUUID, err.Error())
+		}
+
+		taskRef, err := convergedv4.CallAPI[*vmmModels.CloneVmApiResponse, vmmPrismModels.TaskReference](
+			sdkClient.VmApiInstance.CloneVm(&sourceUUID, params, args),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error cloning VM: %s", err.Error())
+		}
+
+		vmUUIDs, err = waitForTaskEntities(ctx, sdkClient, taskRef, "vm")
+		if err != nil {
+			return nil, fmt.Errorf("error waiting for VM clone: %s", err.Error())
+		}
+	} else {
+		recoveryPoint, err := findVMRecoveryPoint(sdkClient, sourceUUID, vmConfig.SourceRecoveryPoint)
+		if err != nil {
+			return nil, fmt.Errorf("error while finding source recovery point: %s", err.Error())
+		}
+
+		log.Printf("restoring vm %s from recovery point %s of vm %s...", d.Config.VMName, *recoveryPoint.ExtId, sourceUUID)
+
+		// Restored VMs are provisioned without categories unless given
+		overrideSpec := vmmModels.NewVmConfigOverrideSpecification()
+		overrideSpec.Name = v4vm.Name
+		overrideSpec.Categories = v4vm.Categories
+		overrideSpec.OwnershipInfo = v4vm.OwnershipInfo
+
+		params := vmmModels.NewRestoreVmRecoveryPointParams()
+		params.VmConfigOverrideSpec = overrideSpec
+
+		recoveryPointsApi := vmmApi.NewVmRecoveryPointsApi(sdkClient.VmApiInstance.ApiClient)
+		_, args, err := convergedv4.GetEntityAndEtag(recoveryPointsApi.GetVmRecoveryPointByExtId(recoveryPoint.ExtId))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get recovery point %s: %s", *recoveryPoint.ExtId, err.Error())
+		}
+
+		taskRef, err := convergedv4.CallAPI[*vmmModels.RestoreVmRecoveryPointApiResponse, vmmPrismModels.TaskReference](
+			recoveryPointsApi.RestoreVmRecoveryPoint(recoveryPoint.ExtId, params, args),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error restoring recovery point: %s", err.Error())
+		}
+
+		vmUUIDs, err = waitForTaskEntities(ctx, sdkClient, taskRef, "vm")
+		if err != nil {
+			return nil, fmt.Errorf("error waiting for recovery point restore: %s", err.Error())
+		}
+	}
+
+	// The task also references the source VM
+	vmUUID := ""
+	for _, uuid := range vmUUIDs {
+		if uuid != sourceUUID {
+			vmUUID = uuid
 			break
 		}
 	}
+	if vmUUID == "" {
+		return nil, fmt.Errorf("VM clone completed but no VM returned")
+	}
+
+	if vmConfig.SourceRecoveryPoint != "" {
+		if err := d.applyRestoreOverrides(ctx, sdkClient, vmUUID, v4vm); err != nil {
+			return nil, err
+		}
+	} else if len(v4vm.Categories) > 0 {
+		if err := d.CleanCategories(ctx, vmUUID); err != nil {
+			return nil, err
+		}
+
+		params := vmmModels.NewAssociateVmCategoriesParams()
+		params.Categories = v4vm.Categories
+		err = callVMTask(ctx, sdkClient, vmUUID, func(args map[string]interface{}) (*vmmModels.AssociateCategoriesApiResponse, error) {
+			return sdkClient.VmApiInstance.AssociateCategories(&vmUUID, params, args)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to associate categories: %s", err.Error())
+		}
+	}
+
+	v4VMResult, err := v4Client.VMs.Get(ctx, vmUUID)
+	if err != nil {
+		log.Printf("error getting vm after clone: %s", err.Error())
+		return nil, err
+	}
+
+	log.Printf("vm %s cloned successfully (powered off)", vmUUID)
+	return &nutanixInstance{vm: v4VMResult}, nil
+}
+
+// applyRestoreOverrides applies the sizing, NICs and guest customization of the VM request to a
+// VM restored from a recovery point, as the restore API can only override name and categories.
+func (d *NutanixDriver) applyRestoreOverrides(ctx context.Context, sdkClient *v4.Client, vmUUID string, v4vm *vmmModels.Vm) error {
+	vmConfig := d.Config.VmConfig
+
+	if vmConfig.CPU > 0 || vmConfig.Core > 0 || vmConfig.MemoryMB > 0 {
+		vm, err := d.GetVM(ctx, vmUUID)
+		if err != nil {
+			return fmt.Errorf("failed to get restored VM: %s", err.Error())
+		}
+		restoredVM := vm.VM()
+		if vmConfig.CPU > 0 {
+			restoredVM.NumSockets = v4vm.NumSockets
+		}
+		if vmConfig.Core > 0 {
+			restoredVM.NumCoresPerSocket = v4vm.NumCoresPerSocket
+		}
+		if vmConfig.MemoryMB > 0 {
+			restoredVM.MemorySizeBytes = v4vm.MemorySizeBytes
+		}
+		if _, err := d.UpdateVM(ctx, vmUUID, restoredVM); err != nil {
+			return err
+		}
+	}
 
-	if ovaUUID == "" {
-		return "", fmt.Errorf("timeout waiting for OVA entity to appear")
+	if len(v4vm.Nics) > 0 {
+		if err := d.CleanNICs(ctx, vmUUID, false); err != nil {
+			return err
+		}
+		for i := range v4vm.Nics {
+			nic := &v4vm.Nics[i]
+			err := callVMTask(ctx, sdkClient, vmUUID, func(args map[string]interface{}) (*vmmModels.CreateNicApiResponse, error) {
+				return sdkClient.VmApiInstance.CreateNic(&vmUUID, nic, args)
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create NIC %d: %s", i+1, err.Error())
+			}
+		}
 	}
 
-	log.Printf("downloading OVA %s", ovaUUID)
-	fileDetail, err := v4Client.Ovas.GetFile(ctx, ovaUUID)
+	if v4vm.GuestCustomization != nil {
+		err := callVMTask(ctx, sdkClient, vmUUID, func(args map[string]interface{}) (*vmmModels.CustomizeGuestVmApiResponse, error) {
+			return sdkClient.VmApiInstance.CustomizeGuestVm(&vmUUID, v4vm.GuestCustomization, args)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set guest customization: %s", err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (d *NutanixDriver) CreateOVA(ctx context.Context, ovaName string, vmUUID string, diskFileFormat string) (string, error) {
+	v4Client, err := d.getV4Client()
 	if err != nil {
-		return "", fmt.Errorf("error downloading OVA: %s", err.Error())
+		return "", fmt.Errorf("error creating V4 client: %s", err.Error())
+	}
+
+	log.Printf("creating OVA %s from VM %s with disk format %s", ovaName, vmUUID, diskFileFormat)
+
+	vmSource := imageModels.NewOvaVmSource()
+	vmSource.VmExtId = &vmUUID
+
+	switch strings.ToUpper(diskFileFormat) {
+	case "QCOW2":
+		vmSource.DiskFileFormat = imageModels.OVADISKFORMAT_QCOW2.Ref()
+	case "VMDK":
+		vmSource.DiskFileFormat = imageModels.OVADISKFORMAT_VMDK.Ref()
+	default:
+		vmSource.DiskFileFormat = imageModels.OVADISKFORMAT_QCOW2.Ref()
+	}
+
+	ova := imageModels.NewOva()
+	ova.Name = &ovaName
+	if err := ova.SetSource(*vmSource); err != nil {
+		return "", fmt.Errorf("error setting OVA source: %s", err.Error())
+	}
+
+	createdOva, err := v4Client.Ovas.Create(ctx, ova)
+	if err != nil {
+		return "", fmt.Errorf("error creating OVA: %s", err.Error())
+	}
+
+	if createdOva.ExtId == nil {
+		return "", fmt.Errorf("OVA %s has no ExtId", ovaName)
+	}
+
+	log.Printf("OVA %s created successfully (%s)", ovaName, *createdOva.ExtId)
+	return *createdOva.ExtId, nil
+}
+
+// ExportOVA opens the download stream of the OVA with the given UUID from offset, and returns the
+// offset the stream starts at. The file is streamed through the V4 file endpoint, which supports
+// resuming; a failing first request falls back to the converged client download.
+func (d *NutanixDriver) ExportOVA(ctx context.Context, ovaUUID string, offset int64) (io.ReadCloser, int64, error) {
+	log.Printf("downloading OVA %s from byte %d", ovaUUID, offset)
+
+	body, start, err := d.downloadFile(ctx, fmt.Sprintf("/api/vmm/v4.2/content/ovas/%s/file", ovaUUID), offset)
+	if err == nil || offset > 0 {
+		return body, start, err
+	}
+
+	log.Printf("OVA file stream failed (%s), falling back to converged client download", err.Error())
+	v4Client, v4Err := d.getV4TransferClient()
+	if v4Err != nil {
+		return nil, 0, fmt.Errorf("error creating V4 client: %s", v4Err.Error())
+	}
+
+	fileDetail, v4Err := v4Client.Ovas.GetFile(ctx, ovaUUID)
+	if v4Err != nil {
+		return nil, 0, fmt.Errorf("error downloading OVA (%s) and fallback (%s)", err.Error(), v4Err.Error())
 	}
 
 	if fileDetail == nil || fileDetail.Path == nil {
-		return "", fmt.Errorf("OVA download returned no file path")
+		return nil, 0, fmt.Errorf("OVA download returned no file path")
 	}
 
+	localFile, err := openDownloadedFile(*fileDetail.Path)
+	if err != nil {
+		return nil, 0, err
+	}
 	log.Printf("OVA downloaded to: %s", *fileDetail.Path)
-	return *fileDetail.Path, nil
+	return localFile, 0, nil
 }
 
-func (d *NutanixDriver) ExportImage(ctx context.Context, imageUUID string) (io.ReadCloser, error) {
-	log.Printf("downloading image %s", imageUUID)
+// FindOVAs returns the UUIDs of the OVAs with the given name
+func (d *NutanixDriver) FindOVAs(ctx context.Context, ovaName string) ([]string, error) {
+	v4Client, err := d.getV4Client()
+	if err != nil {
+		return nil, fmt.Errorf("error creating V4 client: %s", err.Error())
+	}
 
-	v4Client, err := d.getV4TransferClient()
+	return findOvasByName(ctx, v4Client, ovaName)
+}
+
+// DeleteOVA deletes the OVA with the given UUID. The converged client has no OVA delete, so the
+// V4 SDK is used.
+func (d *NutanixDriver) DeleteOVA(ctx context.Context, ovaUUID string) error {
+	sdkClient, err := d.getV4SDKClient()
+	if err != nil {
+		return fmt.Errorf("error creating V4 SDK client: %s", err.Error())
+	}
+
+	_, args, err := convergedv4.GetEntityAndEtag(sdkClient.OvasApiInstance.GetOvaById(&ovaUUID))
+	if err != nil {
+		return fmt.Errorf("failed to get OVA %s: %s", ovaUUID, err.Error())
+	}
+
+	taskRef, err := convergedv4.CallAPI[*imageModels.DeleteOvaApiResponse, vmmPrismModels.TaskReference](
+		sdkClient.OvasApiInstance.DeleteOvaById(&ovaUUID, args),
+	)
+	if err != nil {
+		return fmt.Errorf("error deleting OVA: %s", err.Error())
+	}
+
+	if err := waitForTask(ctx, sdkClient, taskRef); err != nil {
+		return fmt.Errorf("error waiting for OVA deletion: %s", err.Error())
+	}
+
+	log.Printf("OVA %s deleted successfully", ovaUUID)
+	return nil
+}
+
+// FindTemplates returns the UUIDs of the templates with the given name
+func (d *NutanixDriver) FindTemplates(ctx context.Context, templateName string) ([]string, error) {
+	v4Client, err := d.getV4Client()
 	if err != nil {
 		return nil, fmt.Errorf("error creating V4 client: %s", err.Error())
 	}
 
-	fileDetail, err := v4Client.Images.GetFile(ctx, imageUUID)
+	return findTemplatesByName(ctx, v4Client, templateName)
+}
+
+// DeleteTemplate deletes the template with the given UUID and all its versions. The converged
+// client has no template delete, so the V4 SDK is used.
+func (d *NutanixDriver) DeleteTemplate(ctx context.Context, templateUUID string) error {
+	sdkClient, err := d.getV4SDKClient()
 	if err != nil {
-		log.Printf("v4 image download failed (%s), falling back to v3 download API", err.Error())
-		v3Body, v3Err := d.exportImageViaV3(ctx, imageUUID)
-		if v3Err != nil {
-			return nil, fmt.Errorf("error downloading image via v4 (%s) and v3 fallback (%s)", err.Error(), v3Err.Error())
-		}
-		log.Printf("Image export stream opened via v3 fallback")
-		return v3Body, nil
+		return fmt.Errorf("error creating V4 SDK client: %s", err.Error())
+	}
+
+	_, args, err := convergedv4.GetEntityAndEtag(sdkClient.TemplatesApiInstance.GetTemplateById(&templateUUID))
+	if err != nil {
+		return fmt.Errorf("failed to get template %s: %s", templateUUID, err.Error())
+	}
+
+	taskRef, err := convergedv4.CallAPI[*imageModels.DeleteTemplateApiResponse, vmmPrismModels.TaskReference](
+		sdkClient.TemplatesApiInstance.DeleteTemplateById(&templateUUID, args),
+	)
+	if err != nil {
+		return fmt.Errorf("error deleting template: %s", err.Error())
+	}
+
+	if err := waitForTask(ctx, sdkClient, taskRef); err != nil {
+		return fmt.Errorf("error waiting for template deletion: %s", err.Error())
+	}
+
+	log.Printf("template %s deleted successfully", templateUUID)
+	return nil
+}
+
+// ExportImage opens the download stream of the image with the given UUID from offset, and returns
+// the offset the stream starts at. The file is streamed through the V4 file endpoint, which supports
+// resuming; a failing request falls back to the V3 file endpoint, and for downloads from the start
+// to the converged client download, which can't be resumed.
+func (d *NutanixDriver) ExportImage(ctx context.Context, imageUUID string, offset int64) (io.ReadCloser, int64, error) {
+	log.Printf("downloading image %s from byte %d", imageUUID, offset)
+
+	body, start, err := d.downloadFile(ctx, fmt.Sprintf("/api/vmm/v4.2/content/images/%s/file", imageUUID), offset)
+	if err == nil {
+		return body, start, nil
+	}
+
+	log.Printf("image file stream failed (%s), falling back to v3 download API", err.Error())
+	body, start, v3Err := d.exportImageViaV3(ctx, imageUUID, offset)
+	if v3Err == nil {
+		return body, start, nil
+	}
+	if offset > 0 {
+		return nil, 0, fmt.Errorf("error downloading image (%s) and v3 fallback (%s)", err.Error(), v3Err.Error())
+	}
+
+	log.Printf("v3 image download failed (%s), falling back to converged client download", v3Err.Error())
+	v4Client, v4Err := d.getV4TransferClient()
+	if v4Err != nil {
+		return nil, 0, fmt.Errorf("error creating V4 client: %s", v4Err.Error())
+	}
+
+	fileDetail, v4Err := v4Client.Images.GetFile(ctx, imageUUID)
+	if v4Err != nil {
+		return nil, 0, fmt.Errorf("error downloading image (%s), v3 fallback (%s) and converged fallback (%s)", err.Error(), v3Err.Error(), v4Err.Error())
 	}
 
 	if fileDetail == nil || fileDetail.Path == nil {
-		return nil, fmt.Errorf("image download returned no file path")
+		return nil, 0, fmt.Errorf("image download returned no file path")
 	}
 
-	localFile, err := os.Open(*fileDetail.Path)
+	localFile, err := openDownloadedFile(*fileDetail.Path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open downloaded v4 image file %s: %w", *fileDetail.Path, err)
+		return nil, 0, err
 	}
-	log.Printf("Image downloaded via v4 to: %s", *fileDetail.Path)
-	return localFile, nil
+	log.Printf("Image downloaded to: %s", *fileDetail.Path)
+	return localFile, 0, nil
+}
+
+func (d *NutanixDriver) exportImageViaV3(ctx context.Context, imageUUID string, offset int64) (io.ReadCloser, int64, error) {
+	return d.downloadFile(ctx, fmt.Sprintf("/api/nutanix/v3/images/%s/file", imageUUID), offset)
 }
 
-func (d *NutanixDriver) exportImageViaV3(ctx context.Context, imageUUID string) (io.ReadCloser, error) {
+// downloadFile opens the stream of the Prism Central file endpoint path from offset with a Range
+// request, and returns the offset the stream starts at: 0 when the endpoint ignores the range.
+func (d *NutanixDriver) downloadFile(ctx context.Context, path string, offset int64) (io.ReadCloser, int64, error) {
 	configCreds := d.getConfigCreds()
-	url := fmt.Sprintf("https://%s:%d/api/nutanix/v3/images/%s/file", d.ClusterConfig.Endpoint, d.ClusterConfig.Port, imageUUID)
+	url := fmt.Sprintf("https://%s:%d%s", d.ClusterConfig.Endpoint, d.ClusterConfig.Port, path)
 	httpClient := &http.Client{
 		Transport: &http.Transport{
 			TLSClientConfig: &tls.Config{InsecureSkipVerify: d.ClusterConfig.Insecure},
@@ -1325,7 +2119,7 @@ func (d *NutanixDriver) exportImageViaV3(ctx context.Context, imageUUID string)
 	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error creating v3 download request: %w", err)
+		return nil, 0, fmt.Errorf("error creating download request: %w", err)
 	}
 
 	if strings.EqualFold(configCreds.Username, ntnxAPIKeyHeaderName) && configCreds.Password != "" {
@@ -1334,17 +2128,48 @@ func (d *NutanixDriver) exportImageViaV3(ctx context.Context, imageUUID string)
 		req.SetBasicAuth(configCreds.Username, configCreds.Password)
 	}
 
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error executing v3 download request: %w", err)
+		return nil, 0, fmt.Errorf("error executing download request: %w", err)
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		_ = resp.Body.Close()
-		return nil, fmt.Errorf("v3 download request failed with status: %s", resp.Status)
+		return nil, 0, fmt.Errorf("download request failed with status: %s", resp.Status)
+	}
+
+	if offset > 0 && resp.StatusCode != http.StatusPartialContent {
+		log.Printf("download of %s does not support resuming, restarting from the start", path)
+		return resp.Body, 0, nil
 	}
 
-	return resp.Body, nil
+	return resp.Body, offset, nil
+}
+
+// downloadedFile is a file downloaded by the converged client, removed once read.
+type downloadedFile struct {
+	*os.File
+}
+
+func (f *downloadedFile) Close() error {
+	err := f.File.Close()
+	if removeErr := os.Remove(f.Name()); removeErr != nil {
+		log.Printf("failed to remove downloaded file %s: %s", f.Name(), removeErr)
+	}
+	return err
+}
+
+// openDownloadedFile opens a file downloaded by the converged client.
+func openDownloadedFile(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open downloaded file %s: %w", path, err)
+	}
+	return &downloadedFile{File: f}, nil
 }
 
 func (d *NutanixDriver) GetHost(ctx context.Context, hostUUID string) (*nutanixHost, error) {
@@ -1382,17 +2207,12 @@ func (d *NutanixDriver) PowerOff(ctx context.Context, vmUUID string) error {
 	return nil
 }
 
-func (d *NutanixDriver) SaveVMDisk(ctx context.Context, diskUUID string, index int, imageCategories []Category) (*nutanixImage, error) {
+func (d *NutanixDriver) SaveVMDisk(ctx context.Context, diskUUID string, name string, description string, imageCategories []Category) (*nutanixImage, error) {
 	v4Client, err := d.getV4Client()
 	if err != nil {
 		return nil, fmt.Errorf("error creating V4 client: %s", err.Error())
 	}
 
-	name := d.Config.VmConfig.ImageName
-	if index > 0 {
-		name = fmt.Sprintf("%s-disk%d", name, index+1)
-	}
-
 	if d.Config.ForceDeregister || d.Config.FailIfImageExists {
 		log.Println("check if image already exists")
 		images, err := v4Client.Images.List(ctx, converged.WithFilter(fmt.Sprintf("name eq '%s'", name)))
@@ -1434,7 +2254,9 @@ func (d *NutanixDriver) SaveVMDisk(ctx context.Context, diskUUID string, index i
 	}
 
 	imgDescription := defaultImageBuiltDescription
-	if d.Config.ImageDescription != "" {
+	if description != "" {
+		imgDescription = description
+	} else if d.Config.ImageDescription != "" {
 		imgDescription = d.Config.ImageDescription
 	}
 
@@ -1459,6 +2281,15 @@ func (d *NutanixDriver) SaveVMDisk(ctx context.Context, diskUUID string, index i
 		v4Image.CategoryExtIds = categoryExtIds
 	}
 
+	// Without image_cluster_names, Prism places the image on the cluster of the VM disk
+	for _, clusterName := range d.Config.ImageClusterNames {
+		clusterUUID, err := findClusterByName(ctx, v4Client, clusterName)
+		if err != nil {
+			return nil, fmt.Errorf("error while getting image cluster: %s", err.Error())
+		}
+		v4Image.ClusterLocationExtIds = append(v4Image.ClusterLocationExtIds, clusterUUID)
+	}
+
 	log.Printf("creating image %s from VM disk %s...", name, diskUUID)
 	createdImage, err := v4Client.Images.Create(ctx, v4Image)
 	if err != nil {
@@ -1470,6 +2301,177 @@ func (d *NutanixDriver) SaveVMDisk(ctx context.Context, diskUUID string, index i
 	return &nutanixImage{image: createdImage}, nil
 }
 
+// SelectCluster returns the candidate cluster of the selector with the most free resources. The
+// clusters not in normal operation mode, without the GPUs of the VM or without enough free memory
+// and storage for the VM are skipped. The free resources of the candidates and the reason each
+// skipped cluster was not chosen are shown in ui.
+func (d *NutanixDriver) SelectCluster(ctx context.Context, selector ClusterSelector, vm VmConfig, ui packer.Ui) (*clusterCapacity, error) {
+	v4Client, err := d.getV4Client()
+	if err != nil {
+		return nil, fmt.Errorf("error creating V4 client: %s", err.Error())
+	}
+
+	sdkClient, err := d.getV4SDKClient()
+	if err != nil {
+		return nil, fmt.Errorf("error creating V4 client: %s", err.Error())
+	}
+
+	categoryExtIds, err := getCategoryExtIds(ctx, v4Client, selector.Categories)
+	if err != nil {
+		return nil, err
+	}
+
+	clusters, err := v4Client.Clusters.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clusters: %s", err.Error())
+	}
+
+	var candidates []clusterModels.Cluster
+	for _, cluster := range clusters {
+		if cluster.ExtId == nil || cluster.Name == nil || !isPECluster(&cluster) {
+			continue
+		}
+		if len(selector.Names) > 0 && !slices.ContainsFunc(selector.Names, func(name string) bool { return strings.EqualFold(name, *cluster.Name) }) {
+			continue
+		}
+		if len(categoryExtIds) > 0 && !containsAll(cluster.Categories, categoryExtIds) {
+			continue
+		}
+		candidates = append(candidates, cluster)
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no cluster matches the cluster selector")
+	}
+
+	// VMs deployed from a template without memory_mb or vm_disks get the sizing of the template,
+	// which is not known here, so only the configured sizing is checked
+	requiredMemoryBytes := vm.MemoryMB * bytesPerMB
+	var requiredStorageBytes int64
+	for _, disk := range vm.VmDisks {
+		requiredStorageBytes += disk.DiskSizeGB * bytesPerGB
+	}
+	if vm.templateSource() && requiredMemoryBytes == 0 {
+		ui.Say("No memory_mb set for the template VM, free memory of the clusters not checked")
+	}
+
+	var best *clusterCapacity
+	for _, cluster := range candidates {
+		if cluster.Config != nil && cluster.Config.OperationMode != nil && *cluster.Config.OperationMode != clusterModels.OPERATIONMODE_NORMAL {
+			ui.Sayf("Skipping cluster %s: operation mode is %s", *cluster.Name, cluster.Config.OperationMode.GetName())
+			continue
+		}
+
+		missingGPU := ""
+		for _, gpu := range vm.GPU {
+			if _, err := getGPU(ctx, v4Client, gpu.Name, *cluster.ExtId); err != nil {
+				missingGPU = gpu.Name
+				break
+			}
+		}
+		if missingGPU != "" {
+			ui.Sayf("Skipping cluster %s: GPU %s not available", *cluster.Name, missingGPU)
+			continue
+		}
+
+		capacity, err := getClusterCapacity(sdkClient, &cluster)
+		if err != nil {
+			ui.Sayf("Skipping cluster %s: %s", *cluster.Name, err.Error())
+			continue
+		}
+		ui.Sayf("Cluster %s: %d MiB of free memory, %d MHz of free CPU, %d GiB of free storage",
+			capacity.name, capacity.freeMemoryBytes/bytesPerMB, capacity.freeCPUHz/1000000, capacity.freeStorageBytes/bytesPerGB)
+
+		if capacity.freeMemoryBytes < requiredMemoryBytes {
+			ui.Sayf("Skipping cluster %s: %d MiB of memory needed", capacity.name, requiredMemoryBytes/bytesPerMB)
+			continue
+		}
+		if capacity.freeStorageBytes < requiredStorageBytes {
+			ui.Sayf("Skipping cluster %s: %d GiB of storage needed", capacity.name, requiredStorageBytes/bytesPerGB)
+			continue
+		}
+
+		if best == nil || capacity.better(best) {
+			best = capacity
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("none of the %d clusters matching the cluster selector can run the VM", len(candidates))
+	}
+	return best, nil
+}
+
+// EnsureImagePlacementPolicy creates the image placement policy, or updates the one with the same
+// name, placing the images with all the image categories on the clusters with any of the cluster
+// categories.
+func (d *NutanixDriver) EnsureImagePlacementPolicy(ctx context.Context, policy ImagePlacementPolicy, imageCategories []Category) error {
+	v4Client, err := d.getV4Client()
+	if err != nil {
+		return fmt.Errorf("error creating V4 client: %s", err.Error())
+	}
+
+	sdkClient, err := d.getV4SDKClient()
+	if err != nil {
+		return fmt.Errorf("error creating V4 client: %s", err.Error())
+	}
+
+	imageCategoryExtIds, err := getCategoryExtIds(ctx, v4Client, imageCategories)
+	if err != nil {
+		return err
+	}
+
+	clusterCategoryExtIds, err := getCategoryExtIds(ctx, v4Client, policy.ClusterCategories)
+	if err != nil {
+		return err
+	}
+
+	spec := imagesConfig.NewPlacementPolicy()
+	spec.Name = &policy.Name
+	spec.Description = StringPtr(defaultImageBuiltDescription)
+	spec.ImageEntityFilter = imagesConfig.NewFilter()
+	spec.ImageEntityFilter.CategoryExtIds = imageCategoryExtIds
+	spec.ImageEntityFilter.Type = imagesConfig.FILTERMATCHTYPE_CATEGORIES_MATCH_ALL.Ref()
+	spec.ClusterEntityFilter = imagesConfig.NewFilter()
+	spec.ClusterEntityFilter.CategoryExtIds = clusterCategoryExtIds
+	spec.ClusterEntityFilter.Type = imagesConfig.FILTERMATCHTYPE_CATEGORIES_MATCH_ANY.Ref()
+	spec.PlacementType = imagesConfig.PLACEMENTTYPE_SOFT.Ref()
+	if policy.PlacementType == NutanixIdentifierPlacementTypeHard {
+		spec.PlacementType = imagesConfig.PLACEMENTTYPE_HARD.Ref()
+	}
+
+	policiesApi := vmmApi.NewImagePlacementPoliciesApi(sdkClient.ImagesApiInstance.ApiClient)
+	filter := fmt.Sprintf("name eq '%s'", policy.Name)
+	existing, err := convergedv4.CallAPI[*imagesConfig.ListPlacementPoliciesApiResponse, []imagesConfig.PlacementPolicy](
+		policiesApi.ListPlacementPolicies(nil, nil, &filter, nil, nil),
+	)
+	if err != nil {
+		return fmt.Errorf("error listing image placement policies: %s", err.Error())
+	}
+
+	var taskRef vmmPrismModels.TaskReference
+	if len(existing) == 0 || existing[0].ExtId == nil {
+		log.Printf("creating image placement policy %s", policy.Name)
+		taskRef, err = convergedv4.CallAPI[*imagesConfig.CreatePlacementPolicyApiResponse, vmmPrismModels.TaskReference](
+			policiesApi.CreatePlacementPolicy(spec),
+		)
+	} else {
+		log.Printf("updating image placement policy %s (%s)", policy.Name, *existing[0].ExtId)
+		_, args, getErr := convergedv4.GetEntityAndEtag(policiesApi.GetPlacementPolicyById(existing[0].ExtId))
+		if getErr != nil {
+			return fmt.Errorf("failed to get image placement policy %s: %s", policy.Name, getErr.Error())
+		}
+		taskRef, err = convergedv4.CallAPI[*imagesConfig.UpdatePlacementPolicyApiResponse, vmmPrismModels.TaskReference](
+			policiesApi.UpdatePlacementPolicyById(existing[0].ExtId, spec, args),
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("error saving image placement policy %s: %s", policy.Name, err.Error())
+	}
+
+	return waitForTask(ctx, sdkClient, taskRef)
+}
+
 func (d *NutanixDriver) UpdateVM(ctx context.Context, vmUUID string, v4vm *vmmModels.Vm) (*nutanixInstance, error) {
 	v4Client, err := d.getV4Client()
 	if err != nil {
@@ -1518,6 +2520,269 @@ func (d *NutanixDriver) CleanCD(ctx context.Context, vmUUID string) error {
 	return nil
 }
 
+// EjectCdRom ejects the media from a CD-ROM device, keeping the empty drive attached to the VM.
+// The converged client has no eject call, so the V4 SDK VmApi is used directly.
+func (d *NutanixDriver) EjectCdRom(ctx context.Context, vmUUID string, cdromUUID string) error {
+	v4Client, err := d.getV4SDKClient()
+	if err != nil {
+		return fmt.Errorf("failed to get V4 SDK client: %s", err.Error())
+	}
+
+	err = callVMTask(ctx, v4Client, vmUUID, func(args map[string]interface{}) (*vmmModels.EjectCdRomApiResponse, error) {
+		return v4Client.VmApiInstance.EjectCdRomById(&vmUUID, &cdromUUID, args)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to eject CdRom %s: %s", cdromUUID, err.Error())
+	}
+	log.Printf("CdRom %s ejected from VM %s", cdromUUID, vmUUID)
+	return nil
+}
+
+// InsertCdRom inserts an image into an empty CD-ROM device of a VM.
+func (d *NutanixDriver) InsertCdRom(ctx context.Context, vmUUID string, cdromUUID string, imageUUID string) error {
+	v4Client, err := d.getV4SDKClient()
+	if err != nil {
+		return fmt.Errorf("failed to get V4 SDK client: %s", err.Error())
+	}
+
+	imageRef := vmmModels.NewImageReference()
+	imageRef.ImageExtId = &imageUUID
+	dataSourceRef := vmmModels.NewOneOfDataSourceReference()
+	if err := dataSourceRef.SetValue(*imageRef); err != nil {
+		return fmt.Errorf("error setting data source reference: %s", err.Error())
+	}
+	dataSource := vmmModels.NewDataSource()
+	dataSource.Reference = dataSourceRef
+
+	insertParams := vmmModels.NewCdRomInsertParams()
+	insertParams.BackingInfo = vmmModels.NewVmDisk()
+	insertParams.BackingInfo.DataSource = dataSource
+
+	err = callVMTask(ctx, v4Client, vmUUID, func(args map[string]interface{}) (*vmmModels.InsertCdRomApiResponse, error) {
+		return v4Client.VmApiInstance.InsertCdRomById(&vmUUID, &cdromUUID, insertParams, args)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert image %s in CdRom %s: %s", imageUUID, cdromUUID, err.Error())
+	}
+	log.Printf("image %s inserted in CdRom %s of VM %s", imageUUID, cdromUUID, vmUUID)
+	return nil
+}
+
+// CleanNICs removes all NICs from a VM. When resetMac is true the NICs are recreated on
+// the same subnets instead, so they get new MAC addresses. The VM must be powered off.
+func (d *NutanixDriver) CleanNICs(ctx context.Context, vmUUID string, resetMac bool) error {
+	v4Client, err := d.getV4SDKClient()
+	if err != nil {
+		return fmt.Errorf("failed to get V4 SDK client: %s", err.Error())
+	}
+
+	vm, err := d.GetVM(ctx, vmUUID)
+	if err != nil {
+		return fmt.Errorf("failed to get VM for NIC cleanup: %s", err.Error())
+	}
+
+	if len(vm.VM().Nics) == 0 {
+		log.Println("No NICs to clean")
+		return nil
+	}
+
+	log.Printf("Cleaning %d NIC(s) from VM %s", len(vm.VM().Nics), vmUUID)
+	for i, nic := range vm.VM().Nics {
+		if nic.ExtId == nil {
+			log.Printf("NIC %d has no ExtId, skipping", i+1)
+			continue
+		}
+		nicID := *nic.ExtId
+		err = callVMTask(ctx, v4Client, vmUUID, func(args map[string]interface{}) (*vmmModels.DeleteNicApiResponse, error) {
+			return v4Client.VmApiInstance.DeleteNicById(&vmUUID, &nicID, args)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete NIC %d (%s): %s", i+1, nicID, err.Error())
+		}
+		log.Printf("NIC %d (%s) deleted successfully", i+1, nicID)
+
+		if !resetMac {
+			continue
+		}
+
+		// Recreate the NIC on the same subnet, without MAC address or IP
+		netInfo, ok := nic.GetNicNetworkInfo().(vmmModels.VirtualEthernetNicNetworkInfo)
+		if !ok || netInfo.Subnet == nil {
+			return fmt.Errorf("NIC %d (%s) has no subnet to recreate it on", i+1, nicID)
+		}
+		nicNetworkInfo := vmmModels.NewVirtualEthernetNicNetworkInfo()
+		nicNetworkInfo.Subnet = vmmModels.NewSubnetReference()
+		nicNetworkInfo.Subnet.ExtId = netInfo.Subnet.ExtId
+		nicNetworkInfoWrapper := vmmModels.NewOneOfNicNicNetworkInfo()
+		if err := nicNetworkInfoWrapper.SetValue(*nicNetworkInfo); err != nil {
+			return fmt.Errorf("error setting NIC network info: %s", err.Error())
+		}
+		newNic := vmmModels.NewNic()
+		newNic.NicNetworkInfo = nicNetworkInfoWrapper
+
+		err = callVMTask(ctx, v4Client, vmUUID, func(args map[string]interface{}) (*vmmModels.CreateNicApiResponse, error) {
+			return v4Client.VmApiInstance.CreateNic(&vmUUID, newNic, args)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to recreate NIC %d: %s", i+1, err.Error())
+		}
+		log.Printf("NIC %d recreated with a new MAC address", i+1)
+	}
+	return nil
+}
+
+// CleanSerialPorts removes all serial ports from a VM. The VM must be powered off.
+func (d *NutanixDriver) CleanSerialPorts(ctx context.Context, vmUUID string) error {
+	v4Client, err := d.getV4SDKClient()
+	if err != nil {
+		return fmt.Errorf("failed to get V4 SDK client: %s", err.Error())
+	}
+
+	vm, err := d.GetVM(ctx, vmUUID)
+	if err != nil {
+		return fmt.Errorf("failed to get VM for serial port cleanup: %s", err.Error())
+	}
+
+	if len(vm.VM().SerialPorts) == 0 {
+		log.Println("No serial ports to clean")
+		return nil
+	}
+
+	log.Printf("Cleaning %d serial port(s) from VM %s", len(vm.VM().SerialPorts), vmUUID)
+	for i, serialPort := range vm.VM().SerialPorts {
+		if serialPort.ExtId == nil {
+			log.Printf("Serial port %d has no ExtId, skipping", i+1)
+			continue
+		}
+		serialPortID := *serialPort.ExtId
+		err = callVMTask(ctx, v4Client, vmUUID, func(args map[string]interface{}) (*vmmModels.DeleteSerialPortApiResponse, error) {
+			return v4Client.VmApiInstance.DeleteSerialPortById(&vmUUID, &serialPortID, args)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete serial port %d (%s): %s", i+1, serialPortID, err.Error())
+		}
+		log.Printf("Serial port %d (%s) deleted successfully", i+1, serialPortID)
+	}
+	return nil
+}
+
+// CleanGPUs detaches all GPUs from a VM. The VM must be powered off.
+func (d *NutanixDriver) CleanGPUs(ctx context.Context, vmUUID string) error {
+	v4Client, err := d.getV4SDKClient()
+	if err != nil {
+		return fmt.Errorf("failed to get V4 SDK client: %s", err.Error())
+	}
+
+	vm, err := d.GetVM(ctx, vmUUID)
+	if err != nil {
+		return fmt.Errorf("failed to get VM for GPU cleanup: %s", err.Error())
+	}
+
+	if len(vm.VM().Gpus) == 0 {
+		log.Println("No GPUs to clean")
+		return nil
+	}
+
+	log.Printf("Cleaning %d GPU(s) from VM %s", len(vm.VM().Gpus), vmUUID)
+	for i, gpu := range vm.VM().Gpus {
+		if gpu.ExtId == nil {
+			log.Printf("GPU %d has no ExtId, skipping", i+1)
+			continue
+		}
+		gpuID := *gpu.ExtId
+		err = callVMTask(ctx, v4Client, vmUUID, func(args map[string]interface{}) (*vmmModels.DeleteGpuApiResponse, error) {
+			return v4Client.VmApiInstance.DeleteGpuById(&vmUUID, &gpuID, args)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete GPU %d (%s): %s", i+1, gpuID, err.Error())
+		}
+		log.Printf("GPU %d (%s) deleted successfully", i+1, gpuID)
+	}
+	return nil
+}
+
+// CleanCategories disassociates all categories from a VM.
+func (d *NutanixDriver) CleanCategories(ctx context.Context, vmUUID string) error {
+	v4Client, err := d.getV4SDKClient()
+	if err != nil {
+		return fmt.Errorf("failed to get V4 SDK client: %s", err.Error())
+	}
+
+	vm, err := d.GetVM(ctx, vmUUID)
+	if err != nil {
+		return fmt.Errorf("failed to get VM for category cleanup: %s", err.Error())
+	}
+
+	if len(vm.VM().Categories) == 0 {
+		log.Println("No categories to clean")
+		return nil
+	}
+
+	params := vmmModels.NewDisassociateVmCategoriesParams()
+	params.Categories = vm.VM().Categories
+
+	log.Printf("Disassociating %d category(ies) from VM %s", len(params.Categories), vmUUID)
+	err = callVMTask(ctx, v4Client, vmUUID, func(args map[string]interface{}) (*vmmModels.DisassociateCategoriesApiResponse, error) {
+		return v4Client.VmApiInstance.DisassociateCategories(&vmUUID, params, args)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to disassociate categories: %s", err.Error())
+	}
+	return nil
+}
+
+// CleanGuestCustomization removes the cloud-init or sysprep configuration from a VM. Guest
+// customization is staged by Prism and never returned by a VM read, only the CD-ROMs Prism
+// attaches to hand it over to the guest remain on the VM. Those are the cdromIDs recorded when
+// the VM was created, the CD-ROMs emptied or configured since are left in place.
+func (d *NutanixDriver) CleanGuestCustomization(ctx context.Context, vmUUID string, cdromIDs []string) error {
+	v4Client, err := d.getV4Client()
+	if err != nil {
+		return fmt.Errorf("failed to get V4 client: %s", err.Error())
+	}
+
+	vm, err := v4Client.VMs.Get(ctx, vmUUID)
+	if err != nil {
+		return fmt.Errorf("failed to get VM for guest customization cleanup: %s", err.Error())
+	}
+
+	cleaned := 0
+	for i, cdrom := range vm.CdRoms {
+		if cdrom.ExtId == nil || !slices.Contains(cdromIDs, *cdrom.ExtId) {
+			continue
+		}
+		cdromID := *cdrom.ExtId
+		if err := v4Client.VMs.DeleteCdRom(ctx, vmUUID, cdromID); err != nil {
+			return fmt.Errorf("failed to delete guest customization CdRom %d (%s): %s", i+1, cdromID, err.Error())
+		}
+		log.Printf("guest customization CdRom %d (%s) deleted", i+1, cdromID)
+		cleaned++
+	}
+
+	if cleaned == 0 {
+		log.Println("No guest customization to clean")
+	}
+	return nil
+}
+
+// cdromLoaded reports whether media is loaded in a CD-ROM. An empty CD-ROM may still have a
+// backing without data source.
+func cdromLoaded(cdrom *vmmModels.CdRom) bool {
+	return cdrom.BackingInfo != nil && cdrom.BackingInfo.DataSource != nil && cdrom.BackingInfo.DataSource.Reference != nil
+}
+
+// cdromImageUUID returns the UUID of the image loaded in a CD-ROM, empty when the CD-ROM is empty
+// or its media is not backed by an image.
+func cdromImageUUID(cdrom *vmmModels.CdRom) string {
+	if cdrom.BackingInfo == nil || cdrom.BackingInfo.DataSource == nil || cdrom.BackingInfo.DataSource.Reference == nil {
+		return ""
+	}
+	if ref, ok := cdrom.BackingInfo.DataSource.Reference.GetValue().(vmmModels.ImageReference); ok && ref.ImageExtId != nil {
+		return *ref.ImageExtId
+	}
+	return ""
+}
+
 // GenerateConsoleToken obtains a JWT token and WebSocket URI for VNC console access.
 // Uses prism-go-client VMs.GenerateConsoleToken. Used by stepVNCConnect for boot
 // commands over VNC during ISO-based builds.
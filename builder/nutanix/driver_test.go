@@ -0,0 +1,131 @@
+package nutanix
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/hashicorp/packer-plugin-sdk/packer"
+	vmmModels "github.com/nutanix/ntnx-api-golang-clients/vmm-go-client/v4/models/vmm/v4/ahv/config"
+)
+
+// fakeDriver implements the Driver methods used by the step tests and records the calls made,
+// the other methods panic through the nil embedded Driver.
+type fakeDriver struct {
+	Driver
+
+	templates []string
+	ovas      []string
+	cluster   *clusterCapacity
+	vm        *vmmModels.Vm
+
+	calls []string
+}
+
+func (d *fakeDriver) FindTemplates(_ context.Context, name string) ([]string, error) {
+	d.calls = append(d.calls, "FindTemplates "+name)
+	return d.templates, nil
+}
+
+func (d *fakeDriver) CreateTemplate(_ context.Context, vmUUID string, config TemplateConfig) (*nutanixTemplate, error) {
+	d.calls = append(d.calls, "CreateTemplate "+config.Name)
+	return &nutanixTemplate{}, nil
+}
+
+func (d *fakeDriver) DeleteTemplate(_ context.Context, templateUUID string) error {
+	d.calls = append(d.calls, "DeleteTemplate "+templateUUID)
+	return nil
+}
+
+func (d *fakeDriver) FindOVAs(_ context.Context, name string) ([]string, error) {
+	d.calls = append(d.calls, "FindOVAs "+name)
+	return d.ovas, nil
+}
+
+func (d *fakeDriver) CreateOVA(_ context.Context, name string, vmUUID string, format string) (string, error) {
+	d.calls = append(d.calls, "CreateOVA "+name)
+	return "new-ova", nil
+}
+
+func (d *fakeDriver) DeleteOVA(_ context.Context, ovaUUID string) error {
+	d.calls = append(d.calls, "DeleteOVA "+ovaUUID)
+	return nil
+}
+
+func (d *fakeDriver) SelectCluster(_ context.Context, selector ClusterSelector, vm VmConfig, ui packer.Ui) (*clusterCapacity, error) {
+	d.calls = append(d.calls, "SelectCluster")
+	if d.cluster == nil {
+		return nil, errors.New("no cluster matches the cluster selector")
+	}
+	return d.cluster, nil
+}
+
+func (d *fakeDriver) GetVM(_ context.Context, vmUUID string) (*nutanixInstance, error) {
+	d.calls = append(d.calls, "GetVM "+vmUUID)
+	return &nutanixInstance{vm: d.vm}, nil
+}
+
+func (d *fakeDriver) EjectCdRom(_ context.Context, vmUUID string, cdromUUID string) error {
+	d.calls = append(d.calls, "EjectCdRom "+cdromUUID)
+	return nil
+}
+
+func (d *fakeDriver) InsertCdRom(_ context.Context, vmUUID string, cdromUUID string, imageUUID string) error {
+	d.calls = append(d.calls, "InsertCdRom "+cdromUUID+" "+imageUUID)
+	return nil
+}
+
+func (d *fakeDriver) CreateRequest(_ context.Context, vm VmConfig, state multistep.StateBag) (*vmmModels.Vm, error) {
+	d.calls = append(d.calls, "CreateRequest")
+	return vmmModels.NewVm(), nil
+}
+
+func (d *fakeDriver) Create(_ context.Context, request *vmmModels.Vm) (*nutanixInstance, error) {
+	d.calls = append(d.calls, "Create")
+	return d.instance(), nil
+}
+
+func (d *fakeDriver) DeployTemplate(_ context.Context, request *vmmModels.Vm) (*nutanixInstance, *nutanixTemplate, error) {
+	d.calls = append(d.calls, "DeployTemplate")
+	return d.instance(), &nutanixTemplate{}, nil
+}
+
+func (d *fakeDriver) CloneVM(_ context.Context, request *vmmModels.Vm) (*nutanixInstance, error) {
+	d.calls = append(d.calls, "CloneVM")
+	return d.instance(), nil
+}
+
+func (d *fakeDriver) PowerOn(_ context.Context, vmUUID string) error {
+	d.calls = append(d.calls, "PowerOn "+vmUUID)
+	return nil
+}
+
+// instance returns the VM created by the fake driver.
+func (d *fakeDriver) instance() *nutanixInstance {
+	vm := d.vm
+	if vm == nil {
+		vm = vmmModels.NewVm()
+	}
+	vmUUID := "vm"
+	vm.ExtId = &vmUUID
+	return &nutanixInstance{vm: vm}
+}
+
+// testState returns a state bag holding the driver and the UI the steps need.
+func testState(t *testing.T, d Driver) *multistep.BasicStateBag {
+	state := new(multistep.BasicStateBag)
+	state.Put("driver", d)
+	state.Put("ui", packer.TestUi(t))
+	state.Put("ctx", context.Background())
+	return state
+}
+
+// stepFunc is a step running the function, to stand for the steps of the build the tested steps depend on.
+type stepFunc func(multistep.StateBag) multistep.StepAction
+
+func (f stepFunc) Run(_ context.Context, state multistep.StateBag) multistep.StepAction {
+	return f(state)
+}
+
+func (f stepFunc) Cleanup(multistep.StateBag) {}
@@ -0,0 +1,671 @@
+package nutanix
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// exportManifestName is the name of the manifest written next to the exported files.
+const exportManifestName = "export-manifest.json"
+
+// exportedFile describes a file exported by the build in the export manifest.
+type exportedFile struct {
+	Name        string `json:"name"`
+	SourceUUID  string `json:"source_uuid"`
+	Size        int64  `json:"size"`
+	Format      string `json:"format"`
+	Compression string `json:"compression,omitempty"`
+	SHA256      string `json:"sha256"`
+	URL         string `json:"url,omitempty"`
+}
+
+// exportManifest lists the files exported by a build.
+type exportManifest struct {
+	VMName string         `json:"vm_name"`
+	Files  []exportedFile `json:"files"`
+}
+
+// exportPath returns the path of an exported file in the output directory. An empty output
+// directory is the current working directory.
+func exportPath(outputDirectory string, name string) string {
+	if outputDirectory == "" {
+		return name
+	}
+	return filepath.Join(outputDirectory, name)
+}
+
+// exportFileName returns the name of an exported image file for the given format and
+// compression. Raw images keep the historical .img extension.
+func exportFileName(name string, format string, compression string) string {
+	ext := ".img"
+	if format != NutanixIdentifierExportFormatRaw {
+		ext = "." + format
+	}
+
+	switch compression {
+	case NutanixIdentifierExportCompressionGzip:
+		ext += ".gz"
+	case NutanixIdentifierExportCompressionZstd:
+		ext += ".zst"
+	case NutanixIdentifierExportCompressionXz:
+		ext += ".xz"
+	}
+	return name + ext
+}
+
+// compressWriter wraps w in a writer compressing with the given compression. The returned writer
+// must be closed to flush the compressed stream, closing it does not close w.
+func compressWriter(w io.Writer, compression string) (io.WriteCloser, error) {
+	switch compression {
+	case NutanixIdentifierExportCompressionGzip:
+		return gzip.NewWriter(w), nil
+	case NutanixIdentifierExportCompressionZstd:
+		return zstd.NewWriter(w)
+	case NutanixIdentifierExportCompressionXz:
+		return xz.NewWriter(w)
+	}
+	return nopWriteCloser{w}, nil
+}
+
+// nopWriteCloser is the io.WriteCloser of uncompressed exports.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// compressFile writes src compressed with the given compression to dst.
+func compressFile(src string, dst string, compression string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	cw, err := compressWriter(out, compression)
+	if err == nil {
+		_, err = io.Copy(cw, in)
+		if closeErr := cw.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(dst)
+	}
+	return err
+}
+
+// moveFile renames src to dst. Across file systems, where a rename fails with EXDEV, src is
+// copied to dst and removed.
+func moveFile(src string, dst string) error {
+	err := os.Rename(src, dst)
+	var linkErr *os.LinkError
+	if err == nil || !errors.As(err, &linkErr) || !errors.Is(linkErr.Err, syscall.EXDEV) {
+		return err
+	}
+
+	log.Printf("%s and %s are on different file systems, copying", src, dst)
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
+
+// convertImage converts the raw disk image src to dst in the given format with qemu-img.
+func convertImage(ctx context.Context, src string, dst string, format string) error {
+	cmd := exec.CommandContext(ctx, "qemu-img", "convert", "-f", "raw", "-O", format, src, dst)
+	log.Printf("converting image: %s", cmd.String())
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		_ = os.Remove(dst)
+		return fmt.Errorf("qemu-img convert failed: %s: %s", err, string(output))
+	}
+	return nil
+}
+
+// exportOpener opens the download stream of an exported entity from offset, and returns the
+// offset the stream starts at, 0 when the download can't be resumed.
+type exportOpener func(ctx context.Context, offset int64) (io.ReadCloser, int64, error)
+
+// downloadExport downloads the stream opened by open to path, compressed with compression, and
+// returns the number of downloaded bytes. Failed downloads are retried up to retries times.
+// Uncompressed downloads resume from the data already in path, compressed ones restart, and the
+// bytes downloaded again are not added to the progress twice. sum, when not nil, hashes the raw
+// stream of compressed downloads, which have no raw copy to check once written.
+func downloadExport(ctx context.Context, ui packer.Ui, progress *transferProgress, name string, path string, compression string, retries int, sum hash.Hash, open exportOpener) (int64, error) {
+	var offset, counted int64
+	for attempt := 0; ; attempt++ {
+		written, err := downloadExportFrom(ctx, progress, path, compression, offset, counted, sum, open)
+		if err == nil {
+			return written, nil
+		}
+		counted = max(counted, written)
+
+		if ctx.Err() != nil || attempt >= retries {
+			return written, err
+		}
+
+		if compression == "" {
+			offset = written
+		}
+		delay := retryDelay(attempt)
+		ui.Sayf("Download of %s failed (%s), retrying from byte %d in %s...", name, err, offset, delay)
+		if err := sleepContext(ctx, delay); err != nil {
+			return written, err
+		}
+	}
+}
+
+const (
+	// exportRetryDelay is the delay before the first retry of a failed download, doubled on
+	// each retry up to exportRetryMaxDelay.
+	exportRetryDelay    = 2 * time.Second
+	exportRetryMaxDelay = time.Minute
+)
+
+// retryDelay returns the delay before the retry following the failed attempt, attempts
+// starting at 0.
+func retryDelay(attempt int) time.Duration {
+	return min(exportRetryDelay<<min(attempt, 8), exportRetryMaxDelay)
+}
+
+// sleepContext waits for d, or returns the error of ctx when it's done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// downloadExportFrom makes a single download attempt of downloadExport from offset, counted
+// bytes of the stream are already in the progress.
+func downloadExportFrom(ctx context.Context, progress *transferProgress, path string, compression string, offset int64, counted int64, sum hash.Hash, open exportOpener) (int64, error) {
+	body, start, err := open(ctx, offset)
+	if err != nil {
+		return offset, err
+	}
+
+	var source io.Reader = &contextReader{ctx: ctx, ReadCloser: body}
+	if sum != nil {
+		sum.Reset()
+		source = io.TeeReader(source, sum)
+	}
+
+	// Drop whatever follows the resume offset, the stream may restart from the start
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if start > 0 {
+		if err := os.Truncate(path, start); err != nil {
+			_ = body.Close()
+			return 0, err
+		}
+		flags = os.O_WRONLY | os.O_APPEND
+	}
+
+	outFile, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		_ = body.Close()
+		return start, err
+	}
+
+	outWriter, err := compressWriter(outFile, compression)
+	if err != nil {
+		_ = body.Close()
+		_ = outFile.Close()
+		return start, err
+	}
+
+	n, err := io.Copy(outWriter, &progressReader{Reader: source, progress: progress, skip: counted - start})
+	_ = body.Close()
+
+	if closeErr := outWriter.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := outFile.Close(); err == nil {
+		err = closeErr
+	}
+	return start + n, err
+}
+
+// transferProgress reports the bytes transferred by one or more concurrent downloads or uploads
+// as a single progress bar of the Packer UI.
+type transferProgress struct {
+	pw   *io.PipeWriter
+	done chan struct{}
+}
+
+// progressZeros are the bytes fed to the progress bar, only their count matters.
+var progressZeros = make([]byte, 32*1024)
+
+// newTransferProgress starts a progress bar of total bytes. The bar tracks a pipe fed with as many
+// bytes as the transfers report.
+func newTransferProgress(ui packer.Ui, name string, total int64) *transferProgress {
+	pr, pw := io.Pipe()
+	p := &transferProgress{pw: pw, done: make(chan struct{})}
+
+	trackedReader := ui.TrackProgress(name, 0, total, pr)
+	go func() {
+		_, _ = io.Copy(io.Discard, trackedReader)
+		_ = trackedReader.Close()
+		close(p.done)
+	}()
+	return p
+}
+
+// add adds n transferred bytes to the progress.
+func (p *transferProgress) add(n int64) {
+	for n > 0 {
+		chunk := min(n, int64(len(progressZeros)))
+		if _, err := p.pw.Write(progressZeros[:chunk]); err != nil {
+			return
+		}
+		n -= chunk
+	}
+}
+
+// reader returns r reporting the bytes read to the progress.
+func (p *transferProgress) reader(r io.ReadCloser) io.ReadCloser {
+	return readCloser{Reader: &progressReader{Reader: r, progress: p}, Closer: r}
+}
+
+// Close ends the progress bar.
+func (p *transferProgress) Close() {
+	_ = p.pw.Close()
+	<-p.done
+}
+
+// progressReader adds the bytes read to an transferProgress, except the first skip bytes which
+// are already in it.
+type progressReader struct {
+	io.Reader
+	progress *transferProgress
+	skip     int64
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, err := r.Reader.Read(b)
+	added := int64(n)
+	if r.skip > 0 {
+		skipped := min(r.skip, added)
+		r.skip -= skipped
+		added -= skipped
+	}
+	r.progress.add(added)
+	return n, err
+}
+
+// readCloser joins a reader and the closer of its source.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// contextReader stops reading once its context is done, the downloaded files opened by the
+// converged client don't watch it.
+type contextReader struct {
+	io.ReadCloser
+	ctx context.Context
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.ReadCloser.Read(p)
+}
+
+// verifyFileChecksum compares the checksum of the file at path with the expected hex digest.
+func verifyFileChecksum(path string, checksumType string, expected string) error {
+	actual, err := fileChecksum(path, checksumType)
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("%s checksum mismatch: expected %s, got %s", checksumType, expected, actual)
+	}
+	return nil
+}
+
+// newChecksumHash returns the hash of a checksum type.
+func newChecksumHash(checksumType string) (hash.Hash, error) {
+	switch checksumType {
+	case NutanixIdentifierChecksunTypeSHA256:
+		return sha256.New(), nil
+	case NutanixIdentifierChecksunTypeSHA1:
+		return sha1.New(), nil
+	case NutanixIdentifierChecksunTypeSHA512:
+		return sha512.New(), nil
+	case NutanixIdentifierChecksunTypeMD5:
+		return md5.New(), nil
+	}
+	return nil, fmt.Errorf("checksum type %s not supported", checksumType)
+}
+
+// fileChecksum returns the hex encoded checksum of the file at path.
+func fileChecksum(path string, checksumType string) (string, error) {
+	h, err := newChecksumHash(checksumType)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fileChecksums returns the hex encoded checksums of the file at path for several checksum
+// types, reading the file once.
+func fileChecksums(path string, checksumTypes ...string) (map[string]string, error) {
+	hashes := make(map[string]hash.Hash)
+	writers := make([]io.Writer, 0, len(checksumTypes))
+	for _, checksumType := range checksumTypes {
+		if _, ok := hashes[checksumType]; ok {
+			continue
+		}
+		h, err := newChecksumHash(checksumType)
+		if err != nil {
+			return nil, err
+		}
+		hashes[checksumType] = h
+		writers = append(writers, h)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(io.MultiWriter(writers...), f); err != nil {
+		return nil, err
+	}
+
+	sums := make(map[string]string, len(hashes))
+	for checksumType, h := range hashes {
+		sums[checksumType] = hex.EncodeToString(h.Sum(nil))
+	}
+	return sums, nil
+}
+
+// sha256Line returns the sha256sum line of a file.
+func sha256Line(sum string, name string) string {
+	return fmt.Sprintf("%s  %s\n", sum, name)
+}
+
+// writeSHA256Sidecar writes the SHA-256 of the file at path to path.sha256, in the sha256sum
+// format, and returns the checksum.
+func writeSHA256Sidecar(path string) (string, error) {
+	sum, err := fileChecksum(path, NutanixIdentifierChecksunTypeSHA256)
+	if err != nil {
+		return "", fmt.Errorf("error computing checksum of %s: %s", path, err)
+	}
+
+	if err := os.WriteFile(path+".sha256", []byte(sha256Line(sum, filepath.Base(path))), 0644); err != nil {
+		return "", fmt.Errorf("error writing checksum of %s: %s", path, err)
+	}
+	return sum, nil
+}
+
+// exportedFilesMu serializes the updates of the exported files and manifest by parallel exports.
+var exportedFilesMu sync.Mutex
+
+// addExportedFile adds file to the "exported_files" of the state and returns the updated
+// export manifest. exportedFilesMu must be held.
+func addExportedFile(state multistep.StateBag, file exportedFile) ([]byte, error) {
+	config := state.Get("config").(*Config)
+
+	var files []exportedFile
+	if exported, ok := state.GetOk("exported_files"); ok {
+		files = exported.([]exportedFile)
+	}
+	files = append(files, file)
+	state.Put("exported_files", files)
+
+	return json.MarshalIndent(exportManifest{VMName: config.VMName, Files: files}, "", "  ")
+}
+
+// recordExport checksums an exported file, adds it to the "exported_files" of the state and
+// rewrites the export manifest of the output directory.
+func recordExport(state multistep.StateBag, path string, sourceUUID string, format string, compression string) error {
+	config := state.Get("config").(*Config)
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	sum, err := writeSHA256Sidecar(path)
+	if err != nil {
+		return err
+	}
+
+	exportedFilesMu.Lock()
+	defer exportedFilesMu.Unlock()
+
+	manifest, err := addExportedFile(state, exportedFile{
+		Name:        filepath.Base(path),
+		SourceUUID:  sourceUUID,
+		Size:        fi.Size(),
+		Format:      format,
+		Compression: compression,
+		SHA256:      sum,
+	})
+	if err != nil {
+		return err
+	}
+
+	manifestPath := exportPath(config.OutputDirectory, exportManifestName)
+	if err := os.WriteFile(manifestPath, manifest, 0644); err != nil {
+		return fmt.Errorf("error writing export manifest: %s", err)
+	}
+	return nil
+}
+
+// recordS3Export uploads the checksum of a file exported to S3, adds it to the "exported_files"
+// of the state and uploads the updated export manifest.
+func recordS3Export(ctx context.Context, state multistep.StateBag, dest *s3Destination, file exportedFile) error {
+	if err := dest.put(ctx, file.Name+".sha256", []byte(sha256Line(file.SHA256, file.Name))); err != nil {
+		return err
+	}
+
+	exportedFilesMu.Lock()
+	defer exportedFilesMu.Unlock()
+
+	file.URL = dest.url(file.Name)
+	manifest, err := addExportedFile(state, file)
+	if err != nil {
+		return err
+	}
+
+	return dest.put(ctx, exportManifestName, manifest)
+}
+
+// s3Export is the result of an export streamed to S3.
+type s3Export struct {
+	// read is the number of bytes read from the source stream
+	read int64
+	// checksum is the digest of the source stream, when a checksum type is given
+	checksum string
+	// size and sha256 are the size and SHA-256 of the uploaded object
+	size   int64
+	sha256 string
+}
+
+// uploadExport streams r, compressed with compression, to the object name of dest. The source
+// stream is hashed with checksumType when set, and the uploaded object with SHA-256.
+func uploadExport(ctx context.Context, dest *s3Destination, name string, r io.Reader, compression string, checksumType string) (s3Export, error) {
+	var result s3Export
+
+	var sourceHash hash.Hash
+	if checksumType != "" {
+		h, err := newChecksumHash(checksumType)
+		if err != nil {
+			return result, err
+		}
+		sourceHash = h
+		r = io.TeeReader(r, sourceHash)
+	}
+
+	// Compression runs in its own goroutine, writing to the upload through a pipe
+	pr, pw := io.Pipe()
+	copyDone := make(chan error, 1)
+	go func() {
+		cw, err := compressWriter(pw, compression)
+		if err == nil {
+			result.read, err = io.Copy(cw, r)
+			if closeErr := cw.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		_ = pw.CloseWithError(err)
+		copyDone <- err
+	}()
+
+	objectHash := sha256.New()
+	counter := &countingWriter{}
+	err := dest.upload(ctx, name, io.TeeReader(pr, io.MultiWriter(objectHash, counter)))
+	_ = pr.CloseWithError(err)
+	if copyErr := <-copyDone; err == nil {
+		err = copyErr
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if sourceHash != nil {
+		result.checksum = hex.EncodeToString(sourceHash.Sum(nil))
+	}
+	result.size = counter.n
+	result.sha256 = hex.EncodeToString(objectHash.Sum(nil))
+	return result, nil
+}
+
+// countingWriter counts the bytes written to it.
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// resumingReader reads the stream opened by open, and reopens it where it stopped when a read
+// fails, up to retries times with a growing delay. Streamed downloads have no file to resume from.
+type resumingReader struct {
+	ctx     context.Context
+	open    exportOpener
+	retries int
+	failed  int
+	offset  int64
+	body    io.ReadCloser
+}
+
+func (r *resumingReader) Read(p []byte) (int, error) {
+	for {
+		if r.body == nil {
+			if r.failed > 0 {
+				if err := sleepContext(r.ctx, retryDelay(r.failed-1)); err != nil {
+					return 0, err
+				}
+			}
+			body, start, err := r.open(r.ctx, r.offset)
+			if err != nil {
+				if r.retries > 0 && r.ctx.Err() == nil {
+					r.retries--
+					r.failed++
+					log.Printf("download failed (%s), retrying from byte %d", err, r.offset)
+					continue
+				}
+				return 0, err
+			}
+			if start != r.offset {
+				_ = body.Close()
+				return 0, fmt.Errorf("download can't be resumed from byte %d", r.offset)
+			}
+			r.body = body
+		}
+
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		if err == nil || err == io.EOF || r.retries == 0 || r.ctx.Err() != nil {
+			return n, err
+		}
+
+		r.retries--
+		r.failed++
+		log.Printf("download failed (%s), resuming from byte %d", err, r.offset)
+		_ = r.body.Close()
+		r.body = nil
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func (r *resumingReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	return r.body.Close()
+}
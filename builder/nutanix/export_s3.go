@@ -0,0 +1,205 @@
+package nutanix
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// s3Destination uploads files to an S3 compatible object storage.
+type s3Destination struct {
+	client   *s3.Client
+	bucket   string
+	prefix   string
+	partSize int64
+	checksum types.ChecksumAlgorithm
+}
+
+// parseS3URL returns the bucket and key prefix of an s3://bucket/prefix URL.
+func parseS3URL(rawURL string) (string, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	if u.Scheme != "s3" {
+		return "", "", fmt.Errorf("%s is not an s3:// URL", rawURL)
+	}
+
+	if u.Host == "" {
+		return "", "", fmt.Errorf("%s has no bucket", rawURL)
+	}
+
+	return u.Host, strings.Trim(u.Path, "/"), nil
+}
+
+// newS3Destination creates the S3 client of the export destination. Without keys, credentials
+// come from the AWS default chain (environment, shared config, instance role).
+func newS3Destination(ctx context.Context, dest ExportDestination) (*s3Destination, error) {
+	bucket, prefix, err := parseS3URL(dest.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []func(*awsconfig.LoadOptions) error
+	region := dest.Region
+	if region == "" && dest.Endpoint != "" {
+		// S3 compatible storages ignore the region, but requests must be signed with one
+		region = "us-east-1"
+	}
+	if region != "" {
+		opts = append(opts, awsconfig.WithRegion(region))
+	}
+	if dest.AccessKey != "" {
+		opts = append(opts, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(dest.AccessKey, dest.SecretKey, dest.SessionToken)))
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error loading S3 configuration: %s", err)
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		// Custom endpoints are S3 compatible storages like MinIO, which use path style buckets
+		if dest.Endpoint != "" {
+			o.BaseEndpoint = aws.String(dest.Endpoint)
+			o.UsePathStyle = true
+		}
+
+		// Checksums are only sent when asked for, not all S3 compatible storages support them
+		if dest.ChecksumAlgorithm == "" {
+			o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+		}
+	})
+
+	return &s3Destination{
+		client:   client,
+		bucket:   bucket,
+		prefix:   prefix,
+		partSize: int64(dest.PartSize) * 1024 * 1024,
+		checksum: types.ChecksumAlgorithm(dest.ChecksumAlgorithm),
+	}, nil
+}
+
+// key returns the object key of an exported file.
+func (d *s3Destination) key(name string) string {
+	return path.Join(d.prefix, name)
+}
+
+// url returns the s3:// URL of an exported file.
+func (d *s3Destination) url(name string) string {
+	return fmt.Sprintf("s3://%s/%s", d.bucket, d.key(name))
+}
+
+// upload streams r to the object name with a multipart upload. Parts are read and sent one at a
+// time, so the memory used is bound by the part size whatever the object size.
+func (d *s3Destination) upload(ctx context.Context, name string, r io.Reader) error {
+	key := d.key(name)
+	log.Printf("uploading %s to s3://%s/%s", name, d.bucket, key)
+
+	upload, err := d.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:            aws.String(d.bucket),
+		Key:               aws.String(key),
+		ChecksumAlgorithm: d.checksum,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating multipart upload: %s", err)
+	}
+
+	abort := func(err error) error {
+		// The upload is aborted even when the build is cancelled, parts left behind are billed
+		_, abortErr := d.client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(d.bucket),
+			Key:      aws.String(key),
+			UploadId: upload.UploadId,
+		})
+		if abortErr != nil {
+			log.Printf("failed to abort multipart upload of %s: %s", key, abortErr)
+		}
+		return err
+	}
+
+	var parts []types.CompletedPart
+	buf := make([]byte, d.partSize)
+	for partNumber := int32(1); ; partNumber++ {
+		n, readErr := io.ReadFull(r, buf)
+		if readErr == io.EOF && partNumber > 1 {
+			break
+		}
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return abort(readErr)
+		}
+
+		part, err := d.client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:            aws.String(d.bucket),
+			Key:               aws.String(key),
+			UploadId:          upload.UploadId,
+			PartNumber:        aws.Int32(partNumber),
+			Body:              bytes.NewReader(buf[:n]),
+			ChecksumAlgorithm: d.checksum,
+		})
+		if err != nil {
+			return abort(fmt.Errorf("error uploading part %d: %s", partNumber, err))
+		}
+
+		parts = append(parts, types.CompletedPart{
+			ETag:           part.ETag,
+			PartNumber:     aws.Int32(partNumber),
+			ChecksumCRC32:  part.ChecksumCRC32,
+			ChecksumCRC32C: part.ChecksumCRC32C,
+			ChecksumSHA1:   part.ChecksumSHA1,
+			ChecksumSHA256: part.ChecksumSHA256,
+		})
+
+		// A short read is the last part
+		if readErr != nil {
+			break
+		}
+	}
+
+	_, err = d.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(d.bucket),
+		Key:             aws.String(key),
+		UploadId:        upload.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		return abort(fmt.Errorf("error completing multipart upload: %s", err))
+	}
+	return nil
+}
+
+// put uploads a small object name in a single request.
+func (d *s3Destination) put(ctx context.Context, name string, data []byte) error {
+	_, err := d.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:            aws.String(d.bucket),
+		Key:               aws.String(d.key(name)),
+		Body:              bytes.NewReader(data),
+		ChecksumAlgorithm: d.checksum,
+	})
+	if err != nil {
+		return fmt.Errorf("error uploading %s: %s", name, err)
+	}
+	return nil
+}
+
+// delete deletes the object name.
+func (d *s3Destination) delete(ctx context.Context, name string) error {
+	_, err := d.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(d.bucket),
+		Key:    aws.String(d.key(name)),
+	})
+	return err
+}
@@ -4,13 +4,22 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"regexp"
+	"regexp/syntax"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/nutanix-cloud-native/prism-go-client/converged"
 	convergedv4 "github.com/nutanix-cloud-native/prism-go-client/converged/v4"
+	v4 "github.com/nutanix-cloud-native/prism-go-client/v4"
 	clusterModels "github.com/nutanix/ntnx-api-golang-clients/clustermgmt-go-client/v4/models/clustermgmt/v4/config"
+	clusterStats "github.com/nutanix/ntnx-api-golang-clients/clustermgmt-go-client/v4/models/clustermgmt/v4/stats"
 	subnetModels "github.com/nutanix/ntnx-api-golang-clients/networking-go-client/v4/models/networking/v4/config"
+	prismConfig "github.com/nutanix/ntnx-api-golang-clients/prism-go-client/v4/models/prism/v4/config"
+	vmmApi "github.com/nutanix/ntnx-api-golang-clients/vmm-go-client/v4/api"
+	vmmPrismModels "github.com/nutanix/ntnx-api-golang-clients/vmm-go-client/v4/models/prism/v4/config"
 	vmmModels "github.com/nutanix/ntnx-api-golang-clients/vmm-go-client/v4/models/vmm/v4/ahv/config"
 	imageModels "github.com/nutanix/ntnx-api-golang-clients/vmm-go-client/v4/models/vmm/v4/content"
 )
@@ -18,8 +27,6 @@ import (
 // VM helpers
 
 // findVMByUUID finds a VM by UUID using V4 API
-//
-//lint:ignore U1000 kept for future use
 func findVMByUUID(ctx context.Context, client *convergedv4.Client, uuid string) (*vmmModels.Vm, error) {
 	vm, err := client.VMs.Get(ctx, uuid)
 	if err != nil {
@@ -32,8 +39,6 @@ func findVMByUUID(ctx context.Context, client *convergedv4.Client, uuid string)
 }
 
 // findVMByName finds a VM by name using V4 API
-//
-//lint:ignore U1000 kept for future use
 func findVMByName(ctx context.Context, client *convergedv4.Client, name string) (*vmmModels.Vm, error) {
 	vms, err := client.VMs.List(ctx, converged.WithFilter(fmt.Sprintf("name eq '%s'", name)))
 	if err != nil {
@@ -52,8 +57,288 @@ func findVMByName(ctx context.Context, client *convergedv4.Client, name string)
 	return findVMByUUID(ctx, client, *vms[0].ExtId)
 }
 
+// Guest customization helpers
+
+// guestCustomizationFromUserData returns the cloud-init (Linux) or sysprep (Windows) guest
+// customization for the given user data
+func guestCustomizationFromUserData(osType string, userData string) (*vmmModels.GuestCustomizationParams, error) {
+	guestCustomization := vmmModels.NewGuestCustomizationParams()
+
+	if osType == "Linux" {
+		cloudInit := vmmModels.NewCloudInit()
+		userDataScript := vmmModels.NewUserdata()
+		userDataScript.Value = &userData
+		cloudInit.CloudInitScript = vmmModels.NewOneOfCloudInitCloudInitScript()
+		if err := cloudInit.CloudInitScript.SetValue(*userDataScript); err != nil {
+			return nil, fmt.Errorf("error setting cloud-init script: %s", err.Error())
+		}
+		// Directly assign Config to avoid $configItemDiscriminator in JSON
+		guestConfig := vmmModels.NewOneOfGuestCustomizationParamsConfig()
+		if err := guestConfig.SetValue(*cloudInit); err != nil {
+			return nil, fmt.Errorf("error setting guest customization config: %s", err.Error())
+		}
+		guestCustomization.Config = guestConfig
+		log.Printf("CloudInit configured for Linux VM")
+	} else if osType == "Windows" {
+		sysprep := vmmModels.NewSysprep()
+		unattendXml := vmmModels.NewUnattendxml()
+		unattendXml.Value = &userData
+		sysprep.SysprepScript = vmmModels.NewOneOfSysprepSysprepScript()
+		if err := sysprep.SysprepScript.SetValue(*unattendXml); err != nil {
+			return nil, fmt.Errorf("error setting sysprep script: %s", err.Error())
+		}
+		// Directly assign Config to avoid $configItemDiscriminator in JSON
+		guestConfig := vmmModels.NewOneOfGuestCustomizationParamsConfig()
+		if err := guestConfig.SetValue(*sysprep); err != nil {
+			return nil, fmt.Errorf("error setting guest customization config: %s", err.Error())
+		}
+		guestCustomization.Config = guestConfig
+		log.Printf("Sysprep configured for Windows VM")
+	}
+
+	return guestCustomization, nil
+}
+
+// Boot helpers
+
+// bootDeviceTypes maps the boot device identifiers used in the configuration to V4 boot device types
+var bootDeviceTypes = map[string]vmmModels.BootDeviceType{
+	NutanixIdentifierBootPriorityDisk:    vmmModels.BOOTDEVICETYPE_DISK,
+	NutanixIdentifierBootPriorityCDROM:   vmmModels.BOOTDEVICETYPE_CDROM,
+	NutanixIdentifierBootPriorityNetwork: vmmModels.BOOTDEVICETYPE_NETWORK,
+}
+
+// bootOrderFromConfig builds the V4 boot order from boot_order, or from boot_priority when
+// no explicit order is given. CDROM is only included when CdRom devices exist - some Nutanix
+// versions return INTERNAL_ERROR when CDROM is in boot order without CdRom devices.
+func bootOrderFromConfig(vmConfig VmConfig, hasCdRoms bool) []vmmModels.BootDeviceType {
+	devices := vmConfig.BootOrder
+	if len(devices) == 0 {
+		switch vmConfig.BootPriority {
+		case NutanixIdentifierBootPriorityNetwork:
+			devices = []string{NutanixIdentifierBootPriorityNetwork, NutanixIdentifierBootPriorityDisk, NutanixIdentifierBootPriorityCDROM}
+		case NutanixIdentifierBootPriorityDisk:
+			devices = []string{NutanixIdentifierBootPriorityDisk, NutanixIdentifierBootPriorityCDROM, NutanixIdentifierBootPriorityNetwork}
+		default:
+			if !hasCdRoms && vmConfig.BootPriority == NutanixIdentifierBootPriorityCDROM {
+				log.Printf("WARNING: boot_priority is 'cdrom' but no CdRom devices configured, using DISK boot order")
+			}
+			devices = []string{NutanixIdentifierBootPriorityCDROM, NutanixIdentifierBootPriorityDisk, NutanixIdentifierBootPriorityNetwork}
+		}
+	}
+
+	bootOrder := make([]vmmModels.BootDeviceType, 0, len(devices))
+	for _, device := range devices {
+		if device == NutanixIdentifierBootPriorityCDROM && !hasCdRoms {
+			continue
+		}
+		if bootDevice, ok := bootDeviceTypes[device]; ok {
+			bootOrder = append(bootOrder, bootDevice)
+		}
+	}
+	return bootOrder
+}
+
+// diskFirstBootOrder moves DISK to the front of the boot order, keeping the other devices in place
+func diskFirstBootOrder(bootOrder []vmmModels.BootDeviceType) []vmmModels.BootDeviceType {
+	diskFirst := []vmmModels.BootDeviceType{vmmModels.BOOTDEVICETYPE_DISK}
+	for _, device := range bootOrder {
+		if device != vmmModels.BOOTDEVICETYPE_DISK {
+			diskFirst = append(diskFirst, device)
+		}
+	}
+	return diskFirst
+}
+
+// vmBootOrder returns the boot order of a legacy or UEFI VM
+func vmBootOrder(vm *vmmModels.Vm) []vmmModels.BootDeviceType {
+	if vm == nil || vm.BootConfig == nil {
+		return nil
+	}
+	switch bootConfig := vm.BootConfig.GetValue().(type) {
+	case vmmModels.LegacyBoot:
+		return bootConfig.BootOrder
+	case vmmModels.UefiBoot:
+		return bootConfig.BootOrder
+	}
+	return nil
+}
+
+// setVMBootOrder replaces the boot order of a legacy or UEFI VM, keeping the rest of its boot config
+func setVMBootOrder(vm *vmmModels.Vm, bootOrder []vmmModels.BootDeviceType) error {
+	if vm == nil || vm.BootConfig == nil {
+		return fmt.Errorf("vm has no boot config")
+	}
+	switch bootConfig := vm.BootConfig.GetValue().(type) {
+	case vmmModels.LegacyBoot:
+		bootConfig.BootOrder = bootOrder
+		return vm.BootConfig.SetValue(bootConfig)
+	case vmmModels.UefiBoot:
+		bootConfig.BootOrder = bootOrder
+		return vm.BootConfig.SetValue(bootConfig)
+	}
+	return fmt.Errorf("unsupported boot config type")
+}
+
+// Disk helpers
+
+// diskAddress is the bus type and device index of a VM disk or CD-ROM
+type diskAddress struct {
+	busType string
+	index   int
+}
+
+// diskBusTypes maps the bus types used in the configuration to V4 disk bus types
+var diskBusTypes = map[string]vmmModels.DiskBusType{
+	NutanixIdentifierBusTypeSCSI: vmmModels.DISKBUSTYPE_SCSI,
+	NutanixIdentifierBusTypeIDE:  vmmModels.DISKBUSTYPE_IDE,
+	NutanixIdentifierBusTypeSATA: vmmModels.DISKBUSTYPE_SATA,
+	NutanixIdentifierBusTypePCI:  vmmModels.DISKBUSTYPE_PCI,
+}
+
+// cdromBusTypes maps the bus types used in the configuration to V4 CD-ROM bus types
+var cdromBusTypes = map[string]vmmModels.CdRomBusType{
+	NutanixIdentifierBusTypeIDE:  vmmModels.CDROMBUSTYPE_IDE,
+	NutanixIdentifierBusTypeSATA: vmmModels.CDROMBUSTYPE_SATA,
+}
+
+// diskBusType returns the bus type of a disk, defaulting to SATA for CD-ROMs and SCSI for disks
+func diskBusType(disk VmDisk) string {
+	if disk.BusType != "" {
+		return strings.ToLower(disk.BusType)
+	}
+	if disk.ImageType == "ISO_IMAGE" {
+		return NutanixIdentifierBusTypeSATA
+	}
+	return NutanixIdentifierBusTypeSCSI
+}
+
+// diskAddresses returns the address of each disk, in order. Explicit indexes are kept and the
+// other disks get the lowest free index on their bus. Disks and CD-ROMs share the IDE and SATA
+// buses, so they are all allocated together.
+func diskAddresses(disks []VmDisk) ([]diskAddress, error) {
+	used := make(map[diskAddress]bool)
+	for i, disk := range disks {
+		if disk.Index == nil {
+			continue
+		}
+		address := diskAddress{busType: diskBusType(disk), index: *disk.Index}
+		if used[address] {
+			return nil, fmt.Errorf("disk %d: %s index %d is already used by another disk", i, address.busType, address.index)
+		}
+		used[address] = true
+	}
+
+	addresses := make([]diskAddress, len(disks))
+	next := make(map[string]int)
+	for i, disk := range disks {
+		busType := diskBusType(disk)
+		if disk.Index != nil {
+			addresses[i] = diskAddress{busType: busType, index: *disk.Index}
+			continue
+		}
+
+		index := next[busType]
+		for used[diskAddress{busType: busType, index: index}] {
+			index++
+		}
+		addresses[i] = diskAddress{busType: busType, index: index}
+		used[addresses[i]] = true
+		next[busType] = index + 1
+	}
+	return addresses, nil
+}
+
 // Image helpers
 
+// imageChecksum returns the checksum type and hex digest of an image, empty when Prism has none.
+func imageChecksum(img *imageModels.Image) (string, string) {
+	if img == nil || img.Checksum == nil {
+		return "", ""
+	}
+
+	switch cs := img.Checksum.GetValue().(type) {
+	case imageModels.ImageSha256Checksum:
+		if cs.HexDigest != nil {
+			return NutanixIdentifierChecksunTypeSHA256, *cs.HexDigest
+		}
+	case imageModels.ImageSha1Checksum:
+		if cs.HexDigest != nil {
+			return NutanixIdentifierChecksunTypeSHA1, *cs.HexDigest
+		}
+	}
+	return "", ""
+}
+
+// newImageChecksum returns the checksum of an image to create from its type and hex digest.
+func newImageChecksum(checksumType string, digest string) (*imageModels.OneOfImageChecksum, error) {
+	checksum := imageModels.NewOneOfImageChecksum()
+	switch checksumType {
+	case NutanixIdentifierChecksunTypeSHA256:
+		sha256Checksum := imageModels.NewImageSha256Checksum()
+		sha256Checksum.HexDigest = &digest
+		if err := checksum.SetValue(*sha256Checksum); err != nil {
+			return nil, fmt.Errorf("error setting SHA256 checksum: %s", err.Error())
+		}
+	case NutanixIdentifierChecksunTypeSHA1:
+		sha1Checksum := imageModels.NewImageSha1Checksum()
+		sha1Checksum.HexDigest = &digest
+		if err := checksum.SetValue(*sha1Checksum); err != nil {
+			return nil, fmt.Errorf("error setting SHA1 checksum: %s", err.Error())
+		}
+	default:
+		return nil, fmt.Errorf("unsupported checksum type %s", checksumType)
+	}
+	return checksum, nil
+}
+
+// uploadedImageDescription returns the description of an image uploaded from a local file,
+// which carries the SHA-256 of the file so that later builds can find and reuse the image.
+func uploadedImageDescription(digest string) string {
+	return fmt.Sprintf(defaultImageUploadDescription, strings.ToLower(digest))
+}
+
+// findUploadedImage returns the newest ready image uploaded from a file of SHA-256 digest,
+// whatever its name, nil when there is none. Images uploaded before their description carried
+// the digest are found by name and checksum.
+func findUploadedImage(ctx context.Context, client *convergedv4.Client, name string, digest string) (*imageModels.Image, error) {
+	description := uploadedImageDescription(digest)
+	images, err := client.Images.List(ctx, converged.WithFilter(fmt.Sprintf("description eq '%s'", description)))
+	if err != nil {
+		log.Printf("failed to list images by description, looking them up by name only: %s", err)
+	}
+
+	byName, err := client.Images.List(ctx, converged.WithFilter(fmt.Sprintf("name eq '%s'", name)))
+	if err != nil {
+		return nil, err
+	}
+	images = append(images, byName...)
+
+	found := make([]*imageModels.Image, 0)
+	for i := range images {
+		if images[i].ExtId == nil {
+			continue
+		}
+
+		// The reported checksum, when there is one, must match whatever matched the lookup
+		checksumType, checksum := imageChecksum(&images[i])
+		if checksumType == NutanixIdentifierChecksunTypeSHA256 && !strings.EqualFold(checksum, digest) {
+			continue
+		}
+
+		if images[i].Description != nil && *images[i].Description == description ||
+			images[i].Name != nil && strings.EqualFold(*images[i].Name, name) && checksumType == NutanixIdentifierChecksunTypeSHA256 {
+			found = append(found, &images[i])
+		}
+	}
+
+	found = selectNewestReadyImage(found)
+	if len(found) == 0 {
+		return nil, nil
+	}
+	return found[0], nil
+}
+
 // findImageByUUIDHelper finds an image by UUID using V4 API
 func findImageByUUIDHelper(ctx context.Context, client *convergedv4.Client, uuid string) (*imageModels.Image, error) {
 	img, err := client.Images.Get(ctx, uuid)
@@ -100,6 +385,131 @@ func findImageByNameHelper(ctx context.Context, client *convergedv4.Client, name
 	return findImageByUUIDHelper(ctx, client, *found[0].ExtId)
 }
 
+// regexpLiteralPrefix returns the literal every match of a ^ anchored case sensitive regexp starts
+// with, empty when there is none.
+func regexpLiteralPrefix(expr string) string {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return ""
+	}
+
+	re = re.Simplify()
+	if re.Op != syntax.OpConcat || len(re.Sub) < 2 || re.Sub[0].Op != syntax.OpBeginText {
+		return ""
+	}
+	if lit := re.Sub[1]; lit.Op == syntax.OpLiteral && lit.Flags&syntax.FoldCase == 0 {
+		return string(lit.Rune)
+	}
+	return ""
+}
+
+// findImageByFilterHelper finds the image matching a source_image_filter using V4 API. Without
+// most_recent, more than one match is an error. With most_recent, the newest image is picked,
+// ties broken by name then ExtId so that every build picks the same image.
+func findImageByFilterHelper(ctx context.Context, client *convergedv4.Client, filter ImageFilter, imageType string) (*imageModels.Image, error) {
+	var nameRegex *regexp.Regexp
+	if filter.NameRegex != "" {
+		var err error
+		nameRegex, err = regexp.Compile(filter.NameRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid name_regex: %s", err.Error())
+		}
+	}
+
+	var createdAfter time.Time
+	if filter.CreatedAfter != "" {
+		var err error
+		createdAfter, err = time.Parse(time.RFC3339, filter.CreatedAfter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid created_after: %s", err.Error())
+		}
+	}
+
+	categoryExtIds, err := getCategoryExtIds(ctx, client, filter.Categories)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.ImageType != "" {
+		imageType = filter.ImageType
+	}
+
+	// The type and the literal prefix of the name are filtered by Prism, the rest of the filter
+	// is applied to the images returned. The list goes through all the result pages.
+	predicates := []string{fmt.Sprintf("type eq Vmm.Content.ImageType'%s'", imageType)}
+	if prefix := regexpLiteralPrefix(filter.NameRegex); prefix != "" {
+		predicates = append(predicates, fmt.Sprintf("startswith(name, '%s')", strings.ReplaceAll(prefix, "'", "''")))
+	}
+
+	images, err := client.Images.List(ctx, converged.WithFilter(strings.Join(predicates, " and ")))
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]*imageModels.Image, 0)
+	for i := range images {
+		img := &images[i]
+		if img.ExtId == nil || img.Name == nil {
+			continue
+		}
+		if nameRegex != nil && !nameRegex.MatchString(*img.Name) {
+			continue
+		}
+		if img.Type == nil || img.Type.GetName() != imageType {
+			continue
+		}
+		if !createdAfter.IsZero() && (img.CreateTime == nil || img.CreateTime.Before(createdAfter)) {
+			continue
+		}
+		if !containsAll(img.CategoryExtIds, categoryExtIds) {
+			continue
+		}
+		found = append(found, img)
+	}
+
+	found = selectNewestReadyImage(found)
+	if len(found) == 0 {
+		return nil, fmt.Errorf("no ready image matches source_image_filter")
+	}
+
+	if len(found) > 1 && !filter.MostRecent {
+		names := make([]string, len(found))
+		for i, img := range found {
+			names[i] = *img.Name
+		}
+		return nil, fmt.Errorf("%d images match source_image_filter (%s), use most_recent to select the newest", len(found), strings.Join(names, ", "))
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		ti, tj := found[i].CreateTime, found[j].CreateTime
+		if ti != nil && tj != nil && !ti.Equal(*tj) {
+			return ti.After(*tj)
+		}
+		if *found[i].Name != *found[j].Name {
+			return *found[i].Name < *found[j].Name
+		}
+		return *found[i].ExtId < *found[j].ExtId
+	})
+
+	chosen := found[0]
+	createTime := "unknown"
+	if chosen.CreateTime != nil {
+		createTime = chosen.CreateTime.Format(time.RFC3339)
+	}
+	log.Printf("source_image_filter selected image %s (%s) created %s among %d matching image(s)", *chosen.Name, *chosen.ExtId, createTime, len(found))
+	return chosen, nil
+}
+
+// containsAll reports whether all values are in list.
+func containsAll(list []string, values []string) bool {
+	for _, v := range values {
+		if !slices.Contains(list, v) {
+			return false
+		}
+	}
+	return true
+}
+
 // sortImagesByCreateTimeDesc sorts images by CreateTime in descending order
 // (newest first). Images without CreateTime are sorted to the end.
 func sortImagesByCreateTimeDesc(images []*imageModels.Image) {
@@ -141,6 +551,209 @@ func selectNewestReadyImage(images []*imageModels.Image) []*imageModels.Image {
 	return ready[:1]
 }
 
+// pruneTemplateVersions deletes the oldest versions of a template to keep only retention
+// versions. The active version is never deleted.
+func pruneTemplateVersions(ctx context.Context, client *v4.Client, templateUUID string, versions []imageModels.TemplateVersionSpec, retention int) error {
+	sorted := make([]*imageModels.TemplateVersionSpec, 0, len(versions))
+	for i := range versions {
+		sorted = append(sorted, &versions[i])
+	}
+	sortTemplateVersionsByCreateTimeDesc(sorted)
+
+	for _, version := range sorted[min(retention, len(sorted)):] {
+		if version.ExtId == nil {
+			continue
+		}
+		if version.IsActiveVersion != nil && *version.IsActiveVersion {
+			log.Printf("keeping active version %s of template %s", *version.ExtId, templateUUID)
+			continue
+		}
+
+		log.Printf("deleting version %s of template %s", *version.ExtId, templateUUID)
+		_, args, err := convergedv4.GetEntityAndEtag(client.TemplatesApiInstance.GetTemplateVersionById(&templateUUID, version.ExtId))
+		if err != nil {
+			return fmt.Errorf("failed to get version %s of template %s: %s", *version.ExtId, templateUUID, err.Error())
+		}
+		taskRef, err := convergedv4.CallAPI[*imageModels.DeleteTemplateVersionApiResponse, vmmPrismModels.TaskReference](
+			client.TemplatesApiInstance.DeleteTemplateVersionById(&templateUUID, version.ExtId, args),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to delete version %s of template %s: %s", *version.ExtId, templateUUID, err.Error())
+		}
+		if err := waitForTask(ctx, client, taskRef); err != nil {
+			return fmt.Errorf("failed to delete version %s of template %s: %s", *version.ExtId, templateUUID, err.Error())
+		}
+	}
+	return nil
+}
+
+// User helpers
+
+// findUserByName finds an IAM user by username using V4 API and returns its UUID
+func findUserByName(ctx context.Context, client *convergedv4.Client, name string) (string, error) {
+	users, err := client.Users.List(ctx, converged.WithFilter(fmt.Sprintf("username eq '%s'", name)))
+	if err != nil {
+		return "", err
+	}
+
+	for _, user := range users {
+		if user.Username != nil && strings.EqualFold(*user.Username, name) && user.ExtId != nil {
+			return *user.ExtId, nil
+		}
+	}
+	return "", fmt.Errorf("user %s not found", name)
+}
+
+// Recovery point helpers
+
+// findVMRecoveryPoint returns the recovery point of a VM matching recoveryPoint by name or UUID
+func findVMRecoveryPoint(client *v4.Client, vmUUID string, recoveryPoint string) (*vmmModels.VmRecoveryPoint, error) {
+	recoveryPointsApi := vmmApi.NewVmRecoveryPointsApi(client.VmApiInstance.ApiClient)
+
+	filter := fmt.Sprintf("vmExtId eq '%s'", vmUUID)
+	recoveryPoints, err := convergedv4.CallAPI[*vmmModels.ListVmRecoveryPointsApiResponse, []vmmModels.VmRecoveryPoint](
+		recoveryPointsApi.ListVmRecoveryPoints(nil, nil, &filter, nil, nil),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recovery points of VM %s: %s", vmUUID, err.Error())
+	}
+
+	found := make([]*vmmModels.VmRecoveryPoint, 0)
+	for i := range recoveryPoints {
+		if recoveryPoints[i].ExtId == nil || recoveryPoints[i].VmExtId == nil || *recoveryPoints[i].VmExtId != vmUUID {
+			continue
+		}
+		if *recoveryPoints[i].ExtId == recoveryPoint {
+			return &recoveryPoints[i], nil
+		}
+		if recoveryPoints[i].Name != nil && *recoveryPoints[i].Name == recoveryPoint {
+			found = append(found, &recoveryPoints[i])
+		}
+	}
+
+	if len(found) == 0 {
+		return nil, fmt.Errorf("recovery point %s of VM %s not found", recoveryPoint, vmUUID)
+	}
+
+	// Several recovery points may share a name, use the newest one
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].CreationTime == nil || found[j].CreationTime == nil {
+			return found[i].CreationTime != nil
+		}
+		return found[i].CreationTime.After(*found[j].CreationTime)
+	})
+	return found[0], nil
+}
+
+// Template helpers
+
+// findTemplateByUUID finds a template by UUID using V4 API
+func findTemplateByUUID(ctx context.Context, client *convergedv4.Client, uuid string) (*imageModels.Template, error) {
+	template, err := client.Templates.Get(ctx, uuid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find template by UUID %s: %s", uuid, err.Error())
+	}
+	return template, nil
+}
+
+// findTemplatesByName finds all the templates with the given name using V4 API and returns their UUIDs
+func findTemplatesByName(ctx context.Context, client *convergedv4.Client, name string) ([]string, error) {
+	templates, err := client.Templates.List(ctx, converged.WithFilter(fmt.Sprintf("templateName eq '%s'", name)))
+	if err != nil {
+		return nil, err
+	}
+
+	var found []string
+	for _, template := range templates {
+		if template.TemplateName != nil && strings.EqualFold(*template.TemplateName, name) && template.ExtId != nil {
+			found = append(found, *template.ExtId)
+		}
+	}
+	return found, nil
+}
+
+// findTemplateByName finds a template by name using V4 API
+func findTemplateByName(ctx context.Context, client *convergedv4.Client, name string) (*imageModels.Template, error) {
+	found, err := findTemplatesByName(ctx, client, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(found) == 0 {
+		return nil, nil
+	} else if len(found) > 1 {
+		return nil, fmt.Errorf("found more than one template with name %s", name)
+	}
+
+	return findTemplateByUUID(ctx, client, found[0])
+}
+
+// findTemplateVersion returns the template version matching version by name or UUID.
+// The active version of the template is returned when version is empty.
+func findTemplateVersion(client *v4.Client, template *imageModels.Template, version string) (*imageModels.TemplateVersionSpec, error) {
+	if version == "" {
+		if template.TemplateVersionSpec == nil || template.TemplateVersionSpec.ExtId == nil {
+			return nil, fmt.Errorf("template %s has no active version", *template.ExtId)
+		}
+		return template.TemplateVersionSpec, nil
+	}
+
+	versions, err := listTemplateVersions(client, *template.ExtId)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]*imageModels.TemplateVersionSpec, 0)
+	for i := range versions {
+		if versions[i].ExtId == nil {
+			continue
+		}
+		if *versions[i].ExtId == version {
+			return &versions[i], nil
+		}
+		if versions[i].VersionName != nil && *versions[i].VersionName == version {
+			found = append(found, &versions[i])
+		}
+	}
+
+	if len(found) == 0 {
+		return nil, fmt.Errorf("version %s of template %s not found", version, *template.ExtId)
+	}
+
+	// Several versions may share a name, use the newest one
+	sortTemplateVersionsByCreateTimeDesc(found)
+	return found[0], nil
+}
+
+// listTemplateVersions returns all the versions of a template
+func listTemplateVersions(client *v4.Client, templateUUID string) ([]imageModels.TemplateVersionSpec, error) {
+	var versions []imageModels.TemplateVersionSpec
+	limit := 100
+	for page := 0; ; page++ {
+		pageVersions, err := convergedv4.CallAPI[*imageModels.ListTemplateVersionsApiResponse, []imageModels.TemplateVersionSpec](
+			client.TemplatesApiInstance.ListTemplateVersions(&templateUUID, &page, &limit, nil, nil, nil),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list versions of template %s: %s", templateUUID, err.Error())
+		}
+		versions = append(versions, pageVersions...)
+		if len(pageVersions) < limit {
+			return versions, nil
+		}
+	}
+}
+
+// sortTemplateVersionsByCreateTimeDesc sorts template versions by CreateTime in descending
+// order (newest first). Versions without CreateTime are sorted to the end.
+func sortTemplateVersionsByCreateTimeDesc(versions []*imageModels.TemplateVersionSpec) {
+	sort.Slice(versions, func(i, j int) bool {
+		if versions[i].CreateTime == nil || versions[j].CreateTime == nil {
+			return versions[i].CreateTime != nil
+		}
+		return versions[i].CreateTime.After(*versions[j].CreateTime)
+	})
+}
+
 // Cluster helpers
 
 // isPECluster checks if a V4 cluster is a Prism Element (AOS) cluster
@@ -201,6 +814,99 @@ func getClusterUUID(ctx context.Context, client *convergedv4.Client, clusterName
 	return "", fmt.Errorf("cluster name or UUID must be provided")
 }
 
+// clusterCapacity holds the free resources of a candidate cluster of the cluster selector.
+type clusterCapacity struct {
+	uuid             string
+	name             string
+	freeMemoryBytes  int64
+	freeCPUHz        int64
+	freeStorageBytes int64
+}
+
+// better reports whether the cluster has more free resources than other: the most free memory
+// wins, then the most free CPU and the most free storage.
+func (c *clusterCapacity) better(other *clusterCapacity) bool {
+	if c.freeMemoryBytes != other.freeMemoryBytes {
+		return c.freeMemoryBytes > other.freeMemoryBytes
+	}
+	if c.freeCPUHz != other.freeCPUHz {
+		return c.freeCPUHz > other.freeCPUHz
+	}
+	return c.freeStorageBytes > other.freeStorageBytes
+}
+
+// clusterStatsWindow is the period of the cluster stats read to compute the free resources.
+const clusterStatsWindow = 15 * time.Minute
+
+// getClusterCapacity reads the latest memory, CPU and storage stats of a cluster.
+func getClusterCapacity(sdkClient *v4.Client, cluster *clusterModels.Cluster) (*clusterCapacity, error) {
+	endTime := time.Now()
+	startTime := endTime.Add(-clusterStatsWindow)
+	stats, err := convergedv4.CallAPI[*clusterStats.ClusterStatsApiResponse, clusterStats.ClusterStats](
+		sdkClient.ClustersApiInstance.GetClusterStats(cluster.ExtId, &startTime, &endTime, nil, nil, nil),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster stats: %s", err.Error())
+	}
+
+	capacity := &clusterCapacity{
+		uuid:            *cluster.ExtId,
+		name:            *cluster.Name,
+		freeMemoryBytes: latestStat(stats.MemoryCapacityBytes) - latestStat(stats.OverallMemoryUsageBytes),
+		freeCPUHz:       latestStat(stats.CpuCapacityHz) - latestStat(stats.CpuUsageHz),
+	}
+
+	capacity.freeStorageBytes = latestStat(stats.FreePhysicalStorageBytes)
+	if len(stats.FreePhysicalStorageBytes) == 0 {
+		capacity.freeStorageBytes = latestStat(stats.StorageCapacityBytes) - latestStat(stats.StorageUsageBytes)
+	}
+	return capacity, nil
+}
+
+// latestStat returns the most recent value of a stat, 0 when it has none.
+func latestStat(values []clusterStats.TimeValuePair) int64 {
+	var latest *clusterStats.TimeValuePair
+	for i := range values {
+		if values[i].Value == nil || values[i].Timestamp == nil {
+			continue
+		}
+		if latest == nil || values[i].Timestamp.After(*latest.Timestamp) {
+			latest = &values[i]
+		}
+	}
+	if latest == nil {
+		return 0
+	}
+	return *latest.Value
+}
+
+// Storage container helpers
+
+// getStorageContainerUUID resolves a storage container of the cluster by name or UUID.
+func getStorageContainerUUID(ctx context.Context, client *convergedv4.Client, name, uuid, clusterUUID string) (string, error) {
+	if uuid != "" {
+		return uuid, nil
+	}
+
+	containers, err := client.StorageContainers.List(ctx, converged.WithFilter(fmt.Sprintf("name eq '%s'", name)))
+	if err != nil {
+		return "", fmt.Errorf("failed to list storage containers: %s", err.Error())
+	}
+
+	for _, container := range containers {
+		if container.Name == nil || *container.Name != name || container.ClusterExtId == nil || *container.ClusterExtId != clusterUUID {
+			continue
+		}
+		if container.ContainerExtId != nil {
+			return *container.ContainerExtId, nil
+		}
+		if container.ExtId != nil {
+			return *container.ExtId, nil
+		}
+	}
+	return "", fmt.Errorf("storage container %s not found on cluster %s", name, clusterUUID)
+}
+
 // Subnet helpers
 
 const subnetTypeOverlay = "OVERLAY"
@@ -390,6 +1096,77 @@ func findHostByUUID(ctx context.Context, client *convergedv4.Client, hostUUID st
 	return &hosts[0], nil
 }
 
+// Task helpers
+
+// waitForTask waits for a task started through the V4 SDK client to complete
+func waitForTask(ctx context.Context, client *v4.Client, taskRef vmmPrismModels.TaskReference) error {
+	if taskRef.ExtId == nil {
+		return fmt.Errorf("task reference has no ExtId")
+	}
+
+	operation := convergedv4.NewOperation(*taskRef.ExtId, client, converged.NoEntityGetter)
+	_, err := operation.Wait(ctx)
+	return err
+}
+
+// taskProgress returns the progress percentage of a V4 task
+func taskProgress(client *v4.Client, taskUUID string) (int, error) {
+	task, err := convergedv4.CallAPI[*prismConfig.GetTaskApiResponse, prismConfig.Task](client.TasksApiInstance.GetTaskById(&taskUUID, nil))
+	if err != nil {
+		return 0, err
+	}
+
+	if task.ProgressPercentage == nil {
+		return 0, nil
+	}
+	return *task.ProgressPercentage, nil
+}
+
+// waitForTaskEntities waits for a V4 task and returns the UUIDs of the affected entities of
+// the given type (e.g. "vm")
+func waitForTaskEntities(ctx context.Context, client *v4.Client, taskRef vmmPrismModels.TaskReference, entityType string) ([]string, error) {
+	if taskRef.ExtId == nil {
+		return nil, fmt.Errorf("task reference has no ExtId")
+	}
+
+	operation := convergedv4.NewOperation(*taskRef.ExtId, client, converged.NoEntityGetter)
+	if _, err := operation.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	refs, err := operation.GetAffectedEntityRefs()
+	if err != nil {
+		return nil, err
+	}
+	entityRefs, err := convergedv4.GetPrismEntityReferenceSlice(refs)
+	if err != nil {
+		return nil, err
+	}
+
+	var uuids []string
+	for _, ref := range entityRefs {
+		if ref.ExtId != nil && ref.Rel != nil && strings.HasSuffix(*ref.Rel, ":"+entityType) {
+			uuids = append(uuids, *ref.ExtId)
+		}
+	}
+	return uuids, nil
+}
+
+// callVMTask runs a V4 SDK VM call with the current VM ETag and waits for the resulting task
+func callVMTask[R convergedv4.APIResponse](ctx context.Context, client *v4.Client, vmUUID string, call func(args map[string]interface{}) (R, error)) error {
+	_, args, err := convergedv4.GetEntityAndEtag(client.VmApiInstance.GetVmById(&vmUUID))
+	if err != nil {
+		return fmt.Errorf("failed to get VM %s: %s", vmUUID, err.Error())
+	}
+
+	taskRef, err := convergedv4.CallAPI[R, vmmPrismModels.TaskReference](call(args))
+	if err != nil {
+		return err
+	}
+
+	return waitForTask(ctx, client, taskRef)
+}
+
 // Category helpers
 
 // getCategoryExtIds converts category key/value pairs to their V4 ExtIds
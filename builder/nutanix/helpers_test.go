@@ -0,0 +1,79 @@
+package nutanix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiskAddresses(t *testing.T) {
+	tests := []struct {
+		name    string
+		disks   []VmDisk
+		want    []diskAddress
+		wantErr bool
+	}{
+		{
+			name:  "default bus is scsi",
+			disks: []VmDisk{{ImageType: "DISK"}, {ImageType: "DISK_IMAGE"}},
+			want:  []diskAddress{{busType: "scsi", index: 0}, {busType: "scsi", index: 1}},
+		},
+		{
+			name:  "iso images default to sata",
+			disks: []VmDisk{{ImageType: "ISO_IMAGE"}, {ImageType: "DISK"}, {ImageType: "ISO_IMAGE"}},
+			want:  []diskAddress{{busType: "sata", index: 0}, {busType: "scsi", index: 0}, {busType: "sata", index: 1}},
+		},
+		{
+			name:  "bus type is case insensitive",
+			disks: []VmDisk{{ImageType: "DISK", BusType: "SCSI"}, {ImageType: "DISK", BusType: "scsi"}},
+			want:  []diskAddress{{busType: "scsi", index: 0}, {busType: "scsi", index: 1}},
+		},
+		{
+			name:  "explicit indexes are kept and others get the lowest free index",
+			disks: []VmDisk{{ImageType: "DISK", Index: IntPtr(1)}, {ImageType: "DISK"}, {ImageType: "DISK"}},
+			want:  []diskAddress{{busType: "scsi", index: 1}, {busType: "scsi", index: 0}, {busType: "scsi", index: 2}},
+		},
+		{
+			name:  "explicit index after allocated disks",
+			disks: []VmDisk{{ImageType: "DISK"}, {ImageType: "DISK"}, {ImageType: "DISK", Index: IntPtr(0)}},
+			want:  []diskAddress{{busType: "scsi", index: 1}, {busType: "scsi", index: 2}, {busType: "scsi", index: 0}},
+		},
+		{
+			name:  "disks and cd-roms share the ide bus",
+			disks: []VmDisk{{ImageType: "DISK", BusType: "ide"}, {ImageType: "ISO_IMAGE", BusType: "ide"}},
+			want:  []diskAddress{{busType: "ide", index: 0}, {busType: "ide", index: 1}},
+		},
+		{
+			name:  "same index on different buses",
+			disks: []VmDisk{{ImageType: "DISK", Index: IntPtr(0)}, {ImageType: "ISO_IMAGE", Index: IntPtr(0)}},
+			want:  []diskAddress{{busType: "scsi", index: 0}, {busType: "sata", index: 0}},
+		},
+		{
+			name:    "duplicate explicit index",
+			disks:   []VmDisk{{ImageType: "DISK", Index: IntPtr(2)}, {ImageType: "DISK_IMAGE", Index: IntPtr(2)}},
+			wantErr: true,
+		},
+		{
+			name:  "no disks",
+			disks: nil,
+			want:  []diskAddress{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := diskAddresses(tt.disks)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
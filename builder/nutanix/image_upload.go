@@ -0,0 +1,290 @@
+package nutanix
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// newObjectsLiteDestination creates the S3 client of the Objects Lite storage of Prism Central,
+// where local files are uploaded before they are imported as images. Objects Lite authenticates
+// with the Prism credentials, encoded as both the access and the secret key.
+func newObjectsLiteDestination(cluster ClusterConfig) *s3Destination {
+	encoded := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", cluster.Username, cluster.Password)))
+
+	// The configuration is built directly, the AWS default chain would pick host credentials
+	cfg := aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider(encoded, encoded, ""),
+	}
+	if cluster.Insecure {
+		cfg.HTTPClient = &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+			},
+		}
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(fmt.Sprintf(objectsLiteEndpoint, cluster.Endpoint, cluster.Port))
+		o.UsePathStyle = true
+		o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+	})
+
+	return &s3Destination{
+		client:   client,
+		bucket:   objectsLiteBucket,
+		prefix:   objectsLiteKeyPrefix,
+		partSize: objectsLitePartSize,
+	}
+}
+
+// uploadFile uploads the local file path to the object name with a multipart upload, retried up
+// to retries times. Failed attempts are resumed: the parts already uploaded are kept when their
+// ETag is the MD5 of the matching file content. A failed upload is left unfinished, so the next
+// build finds it and resumes it where the storage can list multipart uploads, until it is aborted
+// as stale.
+func (d *s3Destination) uploadFile(ctx context.Context, name string, path string, retries int, progress *transferProgress) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	// Parts kept or uploaded again by a retried attempt are only added once to the progress
+	counted := make(map[int32]bool)
+
+	key := d.key(name)
+	uploadID := d.resumableUpload(ctx, key)
+	for attempt := 0; ; attempt++ {
+		uploadID, err = d.uploadFileParts(ctx, key, f, fi.Size(), uploadID, progress, counted)
+		if err == nil {
+			return nil
+		}
+
+		if ctx.Err() != nil || attempt >= retries {
+			if uploadID != nil {
+				log.Printf("upload %s of %s left unfinished to be resumed", *uploadID, key)
+			}
+			return err
+		}
+		log.Printf("upload of %s failed (%s), resuming", key, err)
+	}
+}
+
+// resumableUpload returns the ID of the latest unfinished multipart upload of key with no activity
+// for objectsLiteIdleUploadAge, nil when there is none or the storage can't list them. Uploads of
+// key with recent activity are being written by another build and are left alone. Only the uploads
+// under the key prefix of the destination belong to the plugin: those with no activity for
+// objectsLiteStaleUploadAge are aborted so failed uploads don't hold storage forever.
+func (d *s3Destination) resumableUpload(ctx context.Context, key string) *string {
+	var latest *types.MultipartUpload
+	var latestIdle time.Duration
+	var stale []types.MultipartUpload
+	paginator := s3.NewListMultipartUploadsPaginator(d.client, &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(d.bucket),
+		Prefix: aws.String(d.prefix + "/"),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			log.Printf("failed to list multipart uploads of %s: %s", d.bucket, err)
+			return nil
+		}
+
+		for _, upload := range page.Uploads {
+			sameKey := aws.ToString(upload.Key) == key
+			if !sameKey && time.Since(aws.ToTime(upload.Initiated)) <= objectsLiteStaleUploadAge {
+				continue
+			}
+
+			idle := time.Since(d.lastUploadActivity(ctx, upload))
+			switch {
+			case idle > objectsLiteStaleUploadAge:
+				stale = append(stale, upload)
+			case !sameKey:
+			case idle <= objectsLiteIdleUploadAge:
+				log.Printf("multipart upload %s of %s is in progress, not resumed", aws.ToString(upload.UploadId), key)
+			case latest == nil || idle < latestIdle:
+				latest = &upload
+				latestIdle = idle
+			}
+		}
+	}
+
+	for _, upload := range stale {
+		log.Printf("aborting stale multipart upload %s of %s", aws.ToString(upload.UploadId), aws.ToString(upload.Key))
+		_, err := d.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(d.bucket),
+			Key:      upload.Key,
+			UploadId: upload.UploadId,
+		})
+		if err != nil {
+			log.Printf("failed to abort multipart upload %s: %s", aws.ToString(upload.UploadId), err)
+		}
+	}
+
+	if latest == nil {
+		return nil
+	}
+	log.Printf("resuming multipart upload %s of %s", aws.ToString(latest.UploadId), key)
+	return latest.UploadId
+}
+
+// lastUploadActivity returns the time the multipart upload was last written to: the latest
+// modification of its parts, or its start when it has none or they can't be listed.
+func (d *s3Destination) lastUploadActivity(ctx context.Context, upload types.MultipartUpload) time.Time {
+	last := aws.ToTime(upload.Initiated)
+	paginator := s3.NewListPartsPaginator(d.client, &s3.ListPartsInput{
+		Bucket:   aws.String(d.bucket),
+		Key:      upload.Key,
+		UploadId: upload.UploadId,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			log.Printf("failed to list uploaded parts of %s: %s", aws.ToString(upload.Key), err)
+			return last
+		}
+		for _, part := range page.Parts {
+			if modified := aws.ToTime(part.LastModified); modified.After(last) {
+				last = modified
+			}
+		}
+	}
+	return last
+}
+
+// uploadedParts returns the parts already uploaded to a multipart upload, none when the storage
+// can't list them.
+func (d *s3Destination) uploadedParts(ctx context.Context, key string, uploadID *string) map[int32]types.Part {
+	parts := make(map[int32]types.Part)
+	paginator := s3.NewListPartsPaginator(d.client, &s3.ListPartsInput{
+		Bucket:   aws.String(d.bucket),
+		Key:      aws.String(key),
+		UploadId: uploadID,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			log.Printf("failed to list uploaded parts of %s: %s", key, err)
+			return parts
+		}
+		for _, part := range page.Parts {
+			parts[aws.ToInt32(part.PartNumber)] = part
+		}
+	}
+	return parts
+}
+
+// uploadFileParts uploads f to the multipart upload uploadID of key, created when nil, skips
+// the parts already uploaded and completes the upload. It returns the ID of the upload. The parts
+// not in counted are added to the progress and to counted.
+func (d *s3Destination) uploadFileParts(ctx context.Context, key string, f *os.File, size int64, uploadID *string, progress *transferProgress, counted map[int32]bool) (*string, error) {
+	uploaded := map[int32]types.Part{}
+	if uploadID == nil {
+		upload, err := d.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+			Bucket:            aws.String(d.bucket),
+			Key:               aws.String(key),
+			ChecksumAlgorithm: d.checksum,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating multipart upload: %s", err)
+		}
+		uploadID = upload.UploadId
+	} else {
+		uploaded = d.uploadedParts(ctx, key, uploadID)
+	}
+
+	partCount := max((size+d.partSize-1)/d.partSize, 1)
+	parts := make([]types.CompletedPart, 0, partCount)
+	for i := int64(0); i < partCount; i++ {
+		partNumber := int32(i + 1)
+		offset := i * d.partSize
+		section := io.NewSectionReader(f, offset, min(d.partSize, size-offset))
+
+		if part, ok := uploaded[partNumber]; ok && aws.ToInt64(part.Size) == section.Size() && sectionMatchesETag(section, aws.ToString(part.ETag)) {
+			parts = append(parts, types.CompletedPart{
+				ETag:           part.ETag,
+				PartNumber:     aws.Int32(partNumber),
+				ChecksumCRC32:  part.ChecksumCRC32,
+				ChecksumCRC32C: part.ChecksumCRC32C,
+				ChecksumSHA1:   part.ChecksumSHA1,
+				ChecksumSHA256: part.ChecksumSHA256,
+			})
+			countPart(progress, counted, partNumber, section.Size())
+			continue
+		}
+
+		part, err := d.client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:            aws.String(d.bucket),
+			Key:               aws.String(key),
+			UploadId:          uploadID,
+			PartNumber:        aws.Int32(partNumber),
+			Body:              section,
+			ChecksumAlgorithm: d.checksum,
+		})
+		if err != nil {
+			return uploadID, fmt.Errorf("error uploading part %d: %s", partNumber, err)
+		}
+
+		parts = append(parts, types.CompletedPart{
+			ETag:           part.ETag,
+			PartNumber:     aws.Int32(partNumber),
+			ChecksumCRC32:  part.ChecksumCRC32,
+			ChecksumCRC32C: part.ChecksumCRC32C,
+			ChecksumSHA1:   part.ChecksumSHA1,
+			ChecksumSHA256: part.ChecksumSHA256,
+		})
+		countPart(progress, counted, partNumber, section.Size())
+	}
+
+	_, err := d.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(d.bucket),
+		Key:             aws.String(key),
+		UploadId:        uploadID,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		return uploadID, fmt.Errorf("error completing multipart upload: %s", err)
+	}
+	return nil, nil
+}
+
+// countPart adds the size of an uploaded part to the progress, once per part.
+func countPart(progress *transferProgress, counted map[int32]bool, partNumber int32, size int64) {
+	if counted[partNumber] {
+		return
+	}
+	counted[partNumber] = true
+	progress.add(size)
+}
+
+// sectionMatchesETag reports whether the ETag of an uploaded part is the MD5 of section.
+func sectionMatchesETag(section *io.SectionReader, etag string) bool {
+	h := md5.New()
+	_, err := io.Copy(h, section)
+	if _, seekErr := section.Seek(0, io.SeekStart); err != nil || seekErr != nil {
+		return false
+	}
+	return strings.EqualFold(strings.Trim(etag, `"`), hex.EncodeToString(h.Sum(nil)))
+}
@@ -0,0 +1,103 @@
+package nutanix
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// stepBootSwitch prepares the VM for the reboot at the end of the OS install once the
+// installer is running: the boot order is rewritten to disk-first and/or the install
+// ISO is ejected from its CD-ROM, so the VM does not boot back into the installer.
+// Network booted VMs always get their boot order switched to disk-first. The switch runs
+// right after the boot command, once the boot switch wait is over.
+type stepBootSwitch struct {
+	Config *Config
+}
+
+func (s *stepBootSwitch) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	diskFirst := s.Config.networkBoot() || s.Config.BootSwitch.DiskFirst
+	ejectCdrom := s.Config.BootSwitch.EjectCdrom
+
+	if !diskFirst && !ejectCdrom {
+		return multistep.ActionContinue
+	}
+
+	ui := state.Get("ui").(packer.Ui)
+	d := state.Get("driver").(Driver)
+	vmUUID := state.Get("vm_uuid").(string)
+
+	// Give the installer time to load before taking its boot device away.
+	if s.Config.BootSwitch.Wait > 0 {
+		ui.Sayf("Waiting %s for installer before switching boot...", s.Config.BootSwitch.Wait.String())
+		select {
+		case <-time.After(s.Config.BootSwitch.Wait):
+		case <-ctx.Done():
+			return multistep.ActionHalt
+		}
+	}
+
+	vm, err := d.GetVM(ctx, vmUUID)
+	if err != nil {
+		err = fmt.Errorf("error getting virtual machine to switch boot: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	v4vm := vm.VM()
+
+	if diskFirst {
+		ui.Say("Switching virtual machine boot order to disk first...")
+
+		bootOrder := diskFirstBootOrder(vmBootOrder(v4vm))
+		if err := setVMBootOrder(v4vm, bootOrder); err != nil {
+			err = fmt.Errorf("error setting boot order: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		if _, err := d.UpdateVM(ctx, vmUUID, v4vm); err != nil {
+			err = fmt.Errorf("error switching boot order: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		log.Printf("boot order of vm %s switched to disk first", vmUUID)
+	}
+
+	if ejectCdrom {
+		// Only the install ISO is ejected, the cd_files and driver ISOs stay loaded for the
+		// installer and the provisioners
+		index, _ := s.Config.VmConfig.installCdromIndex()
+		ui.Sayf("Ejecting install media from virtual machine CD-ROM %d...", index)
+
+		for _, cdrom := range v4vm.CdRoms {
+			if cdrom.ExtId == nil || cdrom.DiskAddress == nil || cdrom.DiskAddress.Index == nil || *cdrom.DiskAddress.Index != index {
+				continue
+			}
+			if !cdromLoaded(&cdrom) {
+				log.Printf("CdRom %d is empty, skipping", index)
+				break
+			}
+			if err := d.EjectCdRom(ctx, vmUUID, *cdrom.ExtId); err != nil {
+				err = fmt.Errorf("error ejecting CdRom %d: %s", index, err)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+			break
+		}
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepBootSwitch) Cleanup(state multistep.StateBag) {
+	// No cleanup needed for boot switch step
+}
@@ -2,9 +2,13 @@ package nutanix
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
 	"github.com/hashicorp/packer-plugin-sdk/packer"
@@ -21,85 +25,225 @@ func (s *stepBuildVM) Run(ctx context.Context, state multistep.StateBag) multist
 	d := state.Get("driver").(Driver)
 	config := state.Get("config").(*Config)
 
-	// Determine if we even have a cd_files disk to attach
-	log.Println("check for CD disk to attach")
-	if cdPathRaw, ok := state.GetOk("cd_path"); ok {
-		ui.Say("Uploading CD disk...")
-		cdFilesPath := cdPathRaw.(string)
-		log.Println("CD disk found " + cdFilesPath)
-		cdfilesImage, err := d.CreateImageFile(ctx, cdFilesPath, config.VmConfig)
+	if action := s.uploadImages(ctx, ui, state, d, config); action != multistep.ActionContinue {
+		return action
+	}
+
+	ui.Say("Creating Packer Builder virtual machine...")
+
+	// Create VM Spec
+	vmRequest, err := d.CreateRequest(ctx, config.VmConfig, state)
+	if err != nil {
+		ui.Error("Error creating virtual machine request: " + err.Error())
+		state.Put("error", err)
+		return multistep.ActionHalt
+	}
+
+	// Create VM, or deploy it from the source template or clone it from the source VM
+	var vmInstance *nutanixInstance
+	if config.VmConfig.templateSource() {
+		var sourceTemplate *nutanixTemplate
+		vmInstance, sourceTemplate, err = d.DeployTemplate(ctx, vmRequest)
 		if err != nil {
-			ui.Error("Error uploading CD disk:" + err.Error())
+			ui.Error("Unable to deploy virtual machine from template: " + err.Error())
 			state.Put("error", err)
 			return multistep.ActionHalt
 		}
-		ui.Sayf("CD disk uploaded %s", cdfilesImage.Name())
-		state.Put("cd_uuid", cdfilesImage.UUID())
-		temp_cd := VmDisk{
-			ImageType:       "ISO_IMAGE",
-			SourceImageUUID: cdfilesImage.UUID(),
+		ui.Sayf("Virtual machine deployed from template %s version %s", sourceTemplate.Name(), sourceTemplate.VersionUUID())
+		state.Put("source_template", sourceTemplate)
+	} else if config.VmConfig.vmSource() {
+		vmInstance, err = d.CloneVM(ctx, vmRequest)
+		if err != nil {
+			ui.Error("Unable to clone virtual machine: " + err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
 		}
-		config.VmConfig.VmDisks = append(config.VmConfig.VmDisks, temp_cd)
+	} else {
+		vmInstance, err = d.Create(ctx, vmRequest)
+		if err != nil {
+			ui.Error("Unable to create virtual machine: " + err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+	}
+	log.Printf("Nutanix VM UUID: %s", vmInstance.UUID())
+
+	// Power on the VM after creation (CdRoms are attached inline via CreateRequest)
+	if err := d.PowerOn(ctx, vmInstance.UUID()); err != nil {
+		ui.Error("Unable to power on virtual machine: " + err.Error())
+		state.Put("error", err)
+		return multistep.ActionHalt
+	}
+	ui.Say(fmt.Sprintf("Virtual machine %s created", config.VMName))
+	state.Put("destroy_vm", true)
+	state.Put("vm_uuid", vmInstance.UUID())
+	state.Put("cluster_uuid", vmInstance.ClusterUUID())
+	// Recorded before any step changes the CD-ROMs, for the guest customization cleanup
+	state.Put("guest_customization_cdroms", vmInstance.GuestCustomizationCdRoms())
+
+	return multistep.ActionContinue
+}
+
+// imageUpload is a local file uploaded as an image before the VM is created.
+type imageUpload struct {
+	path         string
+	checksum     string
+	checksumType string
+	cd           bool  // the cd_files disk
+	disks        []int // indexes in vm_disks of the disks using the file
+	image        *nutanixImage
+	err          error
+}
+
+// uploadImages uploads the cd_files disk and the source_image_path of the disks, up to
+// upload_parallelism at a time. A file used by several disks is uploaded once. When an upload
+// fails, the others are cancelled and the images already uploaded by the step are deleted.
+func (s *stepBuildVM) uploadImages(ctx context.Context, ui packer.Ui, state multistep.StateBag, d Driver, config *Config) multistep.StepAction {
+	var uploads []*imageUpload
+
+	// Determine if we even have a cd_files disk to attach
+	log.Println("check for CD disk to attach")
+	if cdPathRaw, ok := state.GetOk("cd_path"); ok {
+		log.Println("CD disk found " + cdPathRaw.(string))
+		uploads = append(uploads, &imageUpload{path: cdPathRaw.(string), cd: true})
 	} else {
 		log.Println("no CD disk, not attaching.")
 	}
 
-	// Loop through config.VmConfig.VmDisks and upload source_image_path if present
 	log.Println("check for local ISO to upload and attach")
+	byPath := make(map[string]*imageUpload)
 	for i, disk := range config.VmConfig.VmDisks {
 		if disk.SourceImagePath != "" {
-
-			filename := filepath.Base(disk.SourceImagePath)
-
-			ui.Sayf("Uploading %s for disk %d ...", filename, i)
 			log.Println("Disk source image path found: " + disk.SourceImagePath)
-			uploadedImage, err := d.CreateImageFile(ctx, disk.SourceImagePath, config.VmConfig)
-			if err != nil {
-				ui.Error(fmt.Sprintf("Error uploading disk %d: %s", i, err.Error()))
-				state.Put("error", err)
-				return multistep.ActionHalt
+			if upload, ok := byPath[disk.SourceImagePath]; ok {
+				upload.disks = append(upload.disks, i)
+				continue
+			}
+			byPath[disk.SourceImagePath] = &imageUpload{
+				path:         disk.SourceImagePath,
+				checksum:     disk.SourceImageChecksum,
+				checksumType: disk.SourceImageChecksumType,
+				disks:        []int{i},
 			}
-			ui.Say(fmt.Sprintf("Disk %d uploaded: %s", i, uploadedImage.Name()))
-			state.Put(fmt.Sprintf("disk_%d_uuid", i), uploadedImage.UUID())
-			config.VmConfig.VmDisks[i].SourceImageUUID = uploadedImage.UUID()
+			uploads = append(uploads, byPath[disk.SourceImagePath])
 		} else {
 			log.Printf("Disk %d has no source image path, skipping upload.", i)
 		}
 	}
 
-	ui.Say("Creating Packer Builder virtual machine...")
+	if len(uploads) == 0 {
+		return multistep.ActionContinue
+	}
 
-	// Create VM Spec
-	vmRequest, err := d.CreateRequest(ctx, config.VmConfig, state)
-	if err != nil {
-		ui.Error("Error creating virtual machine request: " + err.Error())
-		state.Put("error", err)
-		return multistep.ActionHalt
+	// The first failed upload cancels the others
+	uploadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, max(config.UploadParallelism, 1))
+	var wg sync.WaitGroup
+	for _, upload := range uploads {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-uploadCtx.Done():
+				upload.err = uploadCtx.Err()
+				return
+			}
+
+			if upload.cd {
+				ui.Say("Uploading CD disk...")
+			} else {
+				ui.Sayf("Uploading %s for disk %s ...", filepath.Base(upload.path), diskList(upload.disks))
+			}
+
+			upload.image, upload.err = d.CreateImageFile(uploadCtx, upload.path, upload.checksum, upload.checksumType, config.VmConfig, ui)
+			if upload.err != nil {
+				cancel()
+			}
+		}()
 	}
+	wg.Wait()
 
-	// Create VM
-	vmInstance, err := d.Create(ctx, vmRequest)
-	if err != nil {
-		ui.Error("Unable to create virtual machine: " + err.Error())
-		state.Put("error", err)
-		return multistep.ActionHalt
+	var failed *imageUpload
+	for _, upload := range uploads {
+		if upload.err != nil && (failed == nil || errors.Is(failed.err, context.Canceled)) {
+			failed = upload
+		}
 	}
-	log.Printf("Nutanix VM UUID: %s", vmInstance.UUID())
 
-	// Power on the VM after creation (CdRoms are attached inline via CreateRequest)
-	if err := d.PowerOn(ctx, vmInstance.UUID()); err != nil {
-		ui.Error("Unable to power on virtual machine: " + err.Error())
-		state.Put("error", err)
+	if failed != nil {
+		// Images reused from a previous build are not ours to delete
+		for _, upload := range uploads {
+			if upload.image == nil || upload.image.reused {
+				continue
+			}
+			log.Printf("deleting uploaded image %s", upload.image.UUID())
+			if err := d.DeleteImage(context.WithoutCancel(ctx), upload.image.UUID()); err != nil {
+				ui.Error(fmt.Sprintf("An error occurred while deleting uploaded image %s", upload.image.Name()))
+				log.Println(err)
+			}
+		}
+
+		if ctx.Err() != nil {
+			ui.Say("image upload cancelled")
+			return multistep.ActionHalt
+		}
+
+		if failed.cd {
+			ui.Error("Error uploading CD disk:" + failed.err.Error())
+		} else {
+			ui.Error(fmt.Sprintf("Error uploading disk %s: %s", diskList(failed.disks), failed.err.Error()))
+		}
+		state.Put("error", failed.err)
 		return multistep.ActionHalt
 	}
-	ui.Say(fmt.Sprintf("Virtual machine %s created", config.VMName))
-	state.Put("destroy_vm", true)
-	state.Put("vm_uuid", vmInstance.UUID())
-	state.Put("cluster_uuid", vmInstance.ClusterUUID())
+
+	var reused []string
+	for _, upload := range uploads {
+		if upload.image.reused {
+			reused = append(reused, upload.image.UUID())
+		}
+		for _, i := range upload.disks {
+			ui.Say(fmt.Sprintf("Disk %d uploaded: %s", i, upload.image.Name()))
+			state.Put(fmt.Sprintf("disk_%d_uuid", i), upload.image.UUID())
+			config.VmConfig.VmDisks[i].SourceImageUUID = upload.image.UUID()
+		}
+	}
+	// Reused images are not deleted by source_image_delete
+	state.Put("reused_images", reused)
+
+	// The CD disk is attached after the vm_disks
+	if uploads[0].cd {
+		cdfilesImage := uploads[0].image
+		ui.Sayf("CD disk uploaded %s", cdfilesImage.Name())
+
+		// A reused CD disk may be in use by another build, only uploaded ones are temporary
+		if !cdfilesImage.reused {
+			state.Put("cd_uuid", cdfilesImage.UUID())
+		}
+		temp_cd := VmDisk{
+			ImageType:       "ISO_IMAGE",
+			SourceImageUUID: cdfilesImage.UUID(),
+		}
+		config.VmConfig.VmDisks = append(config.VmConfig.VmDisks, temp_cd)
+	}
 
 	return multistep.ActionContinue
 }
 
+// diskList returns the disk indexes as a comma separated list.
+func diskList(disks []int) string {
+	list := make([]string, len(disks))
+	for i, disk := range disks {
+		list[i] = strconv.Itoa(disk)
+	}
+	return strings.Join(list, ", ")
+}
+
 // Cleanup will tear down the VM once the build is complete
 func (s *stepBuildVM) Cleanup(state multistep.StateBag) {
 	vmUUID := state.Get("vm_uuid")
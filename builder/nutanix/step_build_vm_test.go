@@ -0,0 +1,62 @@
+package nutanix
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+)
+
+func TestStepBuildVM(t *testing.T) {
+	tests := []struct {
+		name               string
+		vm                 VmConfig
+		wantCalls          []string
+		wantSourceTemplate bool
+	}{
+		{
+			name:      "vm is created",
+			vm:        VmConfig{},
+			wantCalls: []string{"CreateRequest", "Create", "PowerOn vm"},
+		},
+		{
+			name:               "vm is deployed from the source template",
+			vm:                 VmConfig{SourceTemplateName: "base"},
+			wantCalls:          []string{"CreateRequest", "DeployTemplate", "PowerOn vm"},
+			wantSourceTemplate: true,
+		},
+		{
+			name:      "vm is cloned from the source vm",
+			vm:        VmConfig{SourceVMName: "reference"},
+			wantCalls: []string{"CreateRequest", "CloneVM", "PowerOn vm"},
+		},
+		{
+			name:      "vm is restored from the recovery point of the source vm",
+			vm:        VmConfig{SourceVMUUID: "uuid", SourceRecoveryPoint: "rp"},
+			wantCalls: []string{"CreateRequest", "CloneVM", "PowerOn vm"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &fakeDriver{}
+			state := testState(t, d)
+			state.Put("config", &Config{VmConfig: tt.vm})
+
+			if action := (&stepBuildVM{}).Run(context.Background(), state); action != multistep.ActionContinue {
+				t.Fatalf("got action %v, want continue: %v", action, state.Get("error"))
+			}
+
+			if !reflect.DeepEqual(d.calls, tt.wantCalls) {
+				t.Errorf("got calls %v, want %v", d.calls, tt.wantCalls)
+			}
+			if vmUUID, _ := state.Get("vm_uuid").(string); vmUUID != "vm" {
+				t.Errorf("got vm_uuid %q, want vm", vmUUID)
+			}
+			if _, ok := state.GetOk("source_template"); ok != tt.wantSourceTemplate {
+				t.Errorf("got source_template recorded %t, want %t", ok, tt.wantSourceTemplate)
+			}
+		})
+	}
+}
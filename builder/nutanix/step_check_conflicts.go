@@ -0,0 +1,82 @@
+package nutanix
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// stepCheckConflicts looks for templates and OVAs with the same name as the ones the build
+// creates, before the VM is built, and applies their on_conflict policy: the build fails,
+// the existing entities are recorded to be deleted at the end of a successful build, or the
+// creation is skipped.
+type stepCheckConflicts struct {
+	Config *Config
+}
+
+func (s *stepCheckConflicts) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	ui := state.Get("ui").(packer.Ui)
+	d := state.Get("driver").(Driver)
+
+	if s.Config.TemplateConfig.Create && s.Config.TemplateConfig.OnConflict != "" {
+		name := s.Config.TemplateConfig.Name
+		existing, err := d.FindTemplates(ctx, name)
+		if err != nil {
+			err = fmt.Errorf("error looking for existing templates %s: %s", name, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		if len(existing) > 0 {
+			switch s.Config.TemplateConfig.OnConflict {
+			case NutanixIdentifierOnConflictFail:
+				err := fmt.Errorf("template %s already exists", name)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			case NutanixIdentifierOnConflictReplace:
+				ui.Sayf("Template %s already exists, it will be replaced", name)
+				state.Put("template_replace", existing)
+			case NutanixIdentifierOnConflictSkip:
+				ui.Sayf("Template %s already exists, template creation will be skipped", name)
+				state.Put("template_skip", true)
+			}
+		}
+	}
+
+	if s.Config.OvaConfig.Create && s.Config.OvaConfig.OnConflict != "" {
+		name := s.Config.OvaConfig.Name
+		existing, err := d.FindOVAs(ctx, name)
+		if err != nil {
+			err = fmt.Errorf("error looking for existing OVAs %s: %s", name, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		if len(existing) > 0 {
+			switch s.Config.OvaConfig.OnConflict {
+			case NutanixIdentifierOnConflictFail:
+				err := fmt.Errorf("OVA %s already exists", name)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			case NutanixIdentifierOnConflictReplace:
+				ui.Sayf("OVA %s already exists, it will be replaced", name)
+				state.Put("ova_replace", existing)
+			case NutanixIdentifierOnConflictSkip:
+				ui.Sayf("OVA %s already exists, OVA creation and export will be skipped", name)
+				state.Put("ova_skip", true)
+			}
+		}
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepCheckConflicts) Cleanup(state multistep.StateBag) {
+	// No cleanup needed for conflict check step
+}
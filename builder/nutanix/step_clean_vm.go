@@ -20,7 +20,7 @@ func (s *stepCleanVM) Run(ctx context.Context, state multistep.StateBag) multist
 	d := state.Get("driver").(Driver)
 	vmUUID := state.Get("vm_uuid").(string)
 
-	if !s.Config.Clean.Cdrom {
+	if !s.Config.Clean.enabled() {
 		log.Printf("No vm cleaning requested, skipping step.")
 		return multistep.ActionContinue
 	}
@@ -28,11 +28,62 @@ func (s *stepCleanVM) Run(ctx context.Context, state multistep.StateBag) multist
 	if s.Config.Clean.Cdrom {
 		ui.Say("Cleaning up CD-ROM in virtual machine...")
 		if err := d.CleanCD(ctx, vmUUID); err != nil {
+			state.Put("error", err)
 			ui.Error("Error removing CdRoms: " + err.Error())
 			return multistep.ActionHalt
 		}
 	}
 
+	if s.Config.Clean.Nics || s.Config.Clean.ResetMac {
+		if s.Config.Clean.ResetMac {
+			ui.Say("Resetting NIC MAC addresses in virtual machine...")
+		} else {
+			ui.Say("Cleaning up NICs in virtual machine...")
+		}
+		if err := d.CleanNICs(ctx, vmUUID, s.Config.Clean.ResetMac); err != nil {
+			state.Put("error", err)
+			ui.Error("Error cleaning NICs: " + err.Error())
+			return multistep.ActionHalt
+		}
+	}
+
+	if s.Config.Clean.SerialPort {
+		ui.Say("Cleaning up serial ports in virtual machine...")
+		if err := d.CleanSerialPorts(ctx, vmUUID); err != nil {
+			state.Put("error", err)
+			ui.Error("Error removing serial ports: " + err.Error())
+			return multistep.ActionHalt
+		}
+	}
+
+	if s.Config.Clean.GPU {
+		ui.Say("Cleaning up GPUs in virtual machine...")
+		if err := d.CleanGPUs(ctx, vmUUID); err != nil {
+			state.Put("error", err)
+			ui.Error("Error removing GPUs: " + err.Error())
+			return multistep.ActionHalt
+		}
+	}
+
+	if s.Config.Clean.Categories {
+		ui.Say("Cleaning up categories of virtual machine...")
+		if err := d.CleanCategories(ctx, vmUUID); err != nil {
+			state.Put("error", err)
+			ui.Error("Error removing categories: " + err.Error())
+			return multistep.ActionHalt
+		}
+	}
+
+	if s.Config.Clean.GuestCustomization {
+		ui.Say("Cleaning up guest customization of virtual machine...")
+		cdromIDs, _ := state.Get("guest_customization_cdroms").([]string)
+		if err := d.CleanGuestCustomization(ctx, vmUUID, cdromIDs); err != nil {
+			state.Put("error", err)
+			ui.Error("Error removing guest customization: " + err.Error())
+			return multistep.ActionHalt
+		}
+	}
+
 	ui.Say("Virtual machine cleaned successfully.")
 	return multistep.ActionContinue
 }
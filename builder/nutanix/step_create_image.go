@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
 	"github.com/hashicorp/packer-plugin-sdk/packer"
@@ -11,13 +12,19 @@ import (
 )
 
 type imageArtefact struct {
-	uuid string
-	size int64
+	uuid         string
+	name         string
+	size         int64
+	checksumType string
+	checksum     string
 }
 
 type diskArtefact struct {
-	uuid string
-	size int64
+	uuid        string
+	size        int64
+	name        string
+	description string
+	categories  []Category
 }
 
 type stepCreateImage struct {
@@ -32,42 +39,74 @@ func (s *stepCreateImage) Run(ctx context.Context, state multistep.StateBag) mul
 
 	ui.Say(fmt.Sprintf("Creating image(s) from virtual machine %s...", s.Config.VMName))
 
-	// Choose disk to replicate - looking for SCSI disks (data disks, not CDROMs)
-	// In V4, CDROMs use SATA bus while data disks use SCSI
+	// Match VM disks with their configuration using the address assigned at
+	// creation, disks without configuration are always saved
+	diskConfigs := make(map[diskAddress]VmDisk)
+	if addresses, err := diskAddresses(s.Config.VmDisks); err == nil {
+		for i, disk := range s.Config.VmDisks {
+			diskConfigs[addresses[i]] = disk
+		}
+	}
+
+	// Choose disks to replicate - CD-ROMs are listed separately in V4, so every
+	// disk is a data disk whatever its bus type
 	var disksToCopy []diskArtefact
 
 	for _, disk := range vm.Disks() {
-		// Only process SCSI disks (data disks)
-		if disk.DiskAddress != nil && disk.DiskAddress.BusType != nil {
-			if disk.DiskAddress.BusType.GetName() == vmmModels.DISKBUSTYPE_SCSI.GetName() {
-				diskUUID := ""
-				if disk.ExtId != nil {
-					diskUUID = *disk.ExtId
-				}
+		if disk.DiskAddress == nil || disk.DiskAddress.BusType == nil {
+			continue
+		}
 
-				// Get disk size from backing info if available
-				var diskSize int64 = 0
-				if disk.BackingInfo != nil {
-					if backingValue := disk.BackingInfo.GetValue(); backingValue != nil {
-						if vmDiskInfo, ok := backingValue.(vmmModels.VmDisk); ok && vmDiskInfo.DiskSizeBytes != nil {
-							diskSize = *vmDiskInfo.DiskSizeBytes
-						}
-					}
-				}
+		diskIndex := 0
+		if disk.DiskAddress.Index != nil {
+			diskIndex = *disk.DiskAddress.Index
+		}
+		diskID := fmt.Sprintf("%s:%d", disk.DiskAddress.BusType.GetName(), diskIndex)
+
+		diskConfig, configured := diskConfigs[diskAddress{busType: strings.ToLower(disk.DiskAddress.BusType.GetName()), index: diskIndex}]
+		if configured && !diskConfig.saveImage() {
+			ui.Say("Skipping disk not saved as image: " + diskID)
+			continue
+		}
 
-				disksToCopy = append(disksToCopy, diskArtefact{
-					uuid: diskUUID,
-					size: diskSize,
-				})
+		diskUUID := ""
+		if disk.ExtId != nil {
+			diskUUID = *disk.ExtId
+		}
 
-				diskIndex := 0
-				if disk.DiskAddress.Index != nil {
-					diskIndex = *disk.DiskAddress.Index
+		// Get disk size from backing info if available
+		var diskSize int64 = 0
+		if disk.BackingInfo != nil {
+			if backingValue := disk.BackingInfo.GetValue(); backingValue != nil {
+				if vmDiskInfo, ok := backingValue.(vmmModels.VmDisk); ok && vmDiskInfo.DiskSizeBytes != nil {
+					diskSize = *vmDiskInfo.DiskSizeBytes
 				}
-				diskID := fmt.Sprintf("SCSI:%d", diskIndex)
-				ui.Say("Found disk to copy: " + diskID)
 			}
 		}
+
+		// Default name is image_name for the first saved disk and image_name-diskN for the others
+		name := diskConfig.OutputImageName
+		if name == "" {
+			name = s.Config.ImageName
+			if len(disksToCopy) > 0 {
+				name = fmt.Sprintf("%s-disk%d", name, len(disksToCopy)+1)
+			}
+		}
+
+		categories := s.Config.ImageCategories
+		if len(diskConfig.OutputImageCategories) > 0 {
+			categories = diskConfig.OutputImageCategories
+		}
+
+		disksToCopy = append(disksToCopy, diskArtefact{
+			uuid:        diskUUID,
+			size:        diskSize,
+			name:        name,
+			description: diskConfig.OutputImageDescription,
+			categories:  categories,
+		})
+
+		ui.Say("Found disk to copy: " + diskID)
 	}
 
 	if len(disksToCopy) == 0 {
@@ -77,20 +116,34 @@ func (s *stepCreateImage) Run(ctx context.Context, state multistep.StateBag) mul
 		return multistep.ActionHalt
 	}
 
+	if s.Config.ImagePlacementPolicy.Name != "" {
+		ui.Say("Updating image placement policy " + s.Config.ImagePlacementPolicy.Name + "...")
+		err := d.EnsureImagePlacementPolicy(ctx, s.Config.ImagePlacementPolicy, s.Config.ImageCategories)
+		if err != nil {
+			ui.Error("Image placement policy update failed: " + err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+	}
+
 	var imageList []imageArtefact
 
-	for i, diskToCopy := range disksToCopy {
+	for _, diskToCopy := range disksToCopy {
 
-		imageResponse, err := d.SaveVMDisk(ctx, diskToCopy.uuid, i, s.Config.ImageCategories)
+		imageResponse, err := d.SaveVMDisk(ctx, diskToCopy.uuid, diskToCopy.name, diskToCopy.description, diskToCopy.categories)
 		if err != nil {
 			ui.Error("Image creation failed: " + err.Error())
 			state.Put("error", err)
 			return multistep.ActionHalt
 		}
 
+		checksumType, checksum := imageResponse.Checksum()
 		imageList = append(imageList, imageArtefact{
-			uuid: imageResponse.UUID(),
-			size: diskToCopy.size,
+			uuid:         imageResponse.UUID(),
+			name:         diskToCopy.name,
+			size:         diskToCopy.size,
+			checksumType: checksumType,
+			checksum:     checksum,
 		})
 
 		ui.Say(fmt.Sprintf("Image successfully created: %s (%s)", imageResponse.Name(), imageResponse.UUID()))
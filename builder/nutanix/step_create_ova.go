@@ -2,6 +2,7 @@ package nutanix
 
 import (
 	"context"
+	"log"
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
 	"github.com/hashicorp/packer-plugin-sdk/packer"
@@ -17,9 +18,14 @@ func (s *StepCreateOVA) Run(ctx context.Context, state multistep.StateBag) multi
 	d := state.Get("driver").(Driver)
 	vmUUID := state.Get("vm_uuid")
 
+	if _, ok := state.GetOk("ova_skip"); ok {
+		ui.Sayf("Skipping creation of existing OVA %s", s.OvaConfig.Name)
+		return multistep.ActionContinue
+	}
+
 	ui.Sayf("Creating OVA for virtual machine %s...", s.VMName)
 
-	err := d.CreateOVA(ctx, s.OvaConfig.Name, vmUUID.(string), s.OvaConfig.Format)
+	ovaUUID, err := d.CreateOVA(ctx, s.OvaConfig.Name, vmUUID.(string), s.OvaConfig.Format)
 
 	if err != nil {
 		ui.Error("OVA creation failed")
@@ -27,7 +33,41 @@ func (s *StepCreateOVA) Run(ctx context.Context, state multistep.StateBag) multi
 		return multistep.ActionHalt
 	}
 
+	state.Put("ova_uuid", ovaUUID)
+
 	return multistep.ActionContinue
 }
 
-func (s *StepCreateOVA) Cleanup(state multistep.StateBag) {}
+// Cleanup deletes the OVA created by the build when the build fails, so no partial
+// artifact is left behind in Prism Central.
+func (s *StepCreateOVA) Cleanup(state multistep.StateBag) {
+	ovaUUID, ok := state.GetOk("ova_uuid")
+	if !ok {
+		return
+	}
+
+	if _, deleted := state.GetOk("ova_deleted"); deleted {
+		return
+	}
+
+	_, cancelled := state.GetOk(multistep.StateCancelled)
+	_, halted := state.GetOk(multistep.StateHalted)
+	if !cancelled && !halted {
+		return
+	}
+
+	ui := state.Get("ui").(packer.Ui)
+	d := state.Get("driver").(Driver)
+	ctx, ok := state.Get("ctx").(context.Context)
+	if !ok {
+		ctx = context.Background()
+	}
+
+	ui.Sayf("Deleting OVA %s...", s.OvaConfig.Name)
+	if err := d.DeleteOVA(ctx, ovaUUID.(string)); err != nil {
+		ui.Error("An error occurred while deleting OVA")
+		log.Println(err)
+		return
+	}
+	ui.Sayf("OVA successfully deleted (%s)", ovaUUID.(string))
+}
@@ -16,9 +16,14 @@ func (s *stepCreateTemplate) Run(ctx context.Context, state multistep.StateBag)
 	vmUUID := state.Get("vm_uuid").(string)
 	d := state.Get("driver").(Driver)
 
+	if _, ok := state.GetOk("template_skip"); ok {
+		ui.Sayf("Skipping creation of existing template %s", s.Config.TemplateConfig.Name)
+		return multistep.ActionContinue
+	}
+
 	ui.Sayf("Creating Template for virtual machine %s...", s.Config.VMName)
 
-	err := d.CreateTemplate(ctx, vmUUID, s.Config.TemplateConfig)
+	template, err := d.CreateTemplate(ctx, vmUUID, s.Config.TemplateConfig)
 	if err != nil {
 		ui.Error("Failed to create template: " + err.Error())
 		state.Put("error", err)
@@ -26,6 +31,7 @@ func (s *stepCreateTemplate) Run(ctx context.Context, state multistep.StateBag)
 	}
 
 	ui.Sayf("Template %s created successfully.", s.Config.TemplateConfig.Name)
+	state.Put("template", template)
 
 	return multistep.ActionContinue
 }
@@ -0,0 +1,48 @@
+package nutanix
+
+import (
+	"context"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// stepDeleteReplaced deletes the templates and OVAs replaced by the build, as the last step of
+// the build so the existing ones are kept when any step before fails. Replaced entities are
+// deleted only when their successor was created.
+type stepDeleteReplaced struct{}
+
+func (s *stepDeleteReplaced) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	ui := state.Get("ui").(packer.Ui)
+	d := state.Get("driver").(Driver)
+
+	// A failed deletion doesn't fail the build: the new template and OVA are kept and the
+	// replaced ones are left to delete by hand
+	if replaced, ok := state.GetOk("template_replace"); ok {
+		if _, created := state.GetOk("template"); created {
+			for _, templateUUID := range replaced.([]string) {
+				ui.Sayf("Deleting replaced template %s...", templateUUID)
+				if err := d.DeleteTemplate(ctx, templateUUID); err != nil {
+					ui.Error("Failed to delete replaced template: " + err.Error())
+				}
+			}
+		}
+	}
+
+	if replaced, ok := state.GetOk("ova_replace"); ok {
+		if _, created := state.GetOk("ova_uuid"); created {
+			for _, ovaUUID := range replaced.([]string) {
+				ui.Sayf("Deleting replaced OVA %s...", ovaUUID)
+				if err := d.DeleteOVA(ctx, ovaUUID); err != nil {
+					ui.Error("Failed to delete replaced OVA: " + err.Error())
+				}
+			}
+		}
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepDeleteReplaced) Cleanup(state multistep.StateBag) {
+	// No cleanup needed for replaced entities deletion step
+}
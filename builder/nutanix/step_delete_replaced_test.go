@@ -0,0 +1,77 @@
+package nutanix
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+)
+
+func TestStepDeleteReplaced(t *testing.T) {
+	config := &Config{
+		TemplateConfig: TemplateConfig{Create: true, Name: "tpl", OnConflict: NutanixIdentifierOnConflictReplace},
+		OvaConfig:      OvaConfig{Create: true, Name: "ova", OnConflict: NutanixIdentifierOnConflictReplace},
+	}
+
+	tests := []struct {
+		name      string
+		lastStep  multistep.StepAction
+		wantCalls []string
+	}{
+		{
+			name:     "replaced entities are deleted after the build succeeds",
+			lastStep: multistep.ActionContinue,
+			wantCalls: []string{
+				"FindTemplates tpl", "FindOVAs ova",
+				"CreateTemplate tpl", "CreateOVA ova",
+				"DeleteTemplate old-tpl", "DeleteOVA old-ova",
+			},
+		},
+		{
+			name:     "replaced entities are kept when a later step fails",
+			lastStep: multistep.ActionHalt,
+			wantCalls: []string{
+				"FindTemplates tpl", "FindOVAs ova",
+				"CreateTemplate tpl", "CreateOVA ova",
+				"DeleteOVA new-ova",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &fakeDriver{templates: []string{"old-tpl"}, ovas: []string{"old-ova"}}
+			state := testState(t, d)
+			state.Put("vm_uuid", "vm")
+
+			runner := &multistep.BasicRunner{Steps: []multistep.Step{
+				&stepCheckConflicts{Config: config},
+				&stepCreateTemplate{Config: config},
+				&StepCreateOVA{OvaConfig: config.OvaConfig},
+				stepFunc(func(multistep.StateBag) multistep.StepAction { return tt.lastStep }),
+				&stepDeleteReplaced{},
+			}}
+			runner.Run(context.Background(), state)
+
+			if !reflect.DeepEqual(d.calls, tt.wantCalls) {
+				t.Errorf("got calls %v, want %v", d.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestStepDeleteReplacedWithoutSuccessor(t *testing.T) {
+	// Nothing is deleted when the template and OVA creation were skipped
+	d := &fakeDriver{}
+	state := testState(t, d)
+	state.Put("template_replace", []string{"old-tpl"})
+	state.Put("ova_replace", []string{"old-ova"})
+
+	if action := (&stepDeleteReplaced{}).Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("got action %v, want continue", action)
+	}
+	if len(d.calls) != 0 {
+		t.Errorf("got calls %v, want none", d.calls)
+	}
+}
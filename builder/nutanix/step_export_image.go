@@ -2,19 +2,30 @@ package nutanix
 
 import (
 	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"log"
 	"os"
-	"os/signal"
-	"syscall"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
 	"github.com/hashicorp/packer-plugin-sdk/packer"
 )
 
 type stepExportImage struct {
-	VMName    string
-	ImageName string
+	VMName          string
+	OutputDirectory string
+	Format          string
+	Compression     string
+	Retries         int
+	Destination     ExportDestination
+	Parallelism     int
 }
 
 func (s *stepExportImage) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
@@ -24,99 +35,262 @@ func (s *stepExportImage) Run(ctx context.Context, state multistep.StateBag) mul
 
 	ui.Say(fmt.Sprintf("Exporting image(s) from virtual machine %s...", s.VMName))
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-
-	for index, imageToExport := range imageList {
-		name := s.ImageName
-		if index > 0 {
-			name = fmt.Sprintf("%s-disk%d", name, index+1)
-		}
-
-		ui.Say(fmt.Sprintf("Downloading image %s...", name))
-
-		exportReader, err := d.ExportImage(ctx, imageToExport.uuid)
-		if err != nil {
-			ui.Error("Image export failed: " + err.Error())
+	// Images are downloaded raw and converted with qemu-img
+	if s.Format != NutanixIdentifierExportFormatRaw {
+		if _, err := exec.LookPath("qemu-img"); err != nil {
+			err = fmt.Errorf("export_format %s needs qemu-img in PATH: %s", s.Format, err)
+			ui.Error(err.Error())
 			state.Put("error", err)
 			return multistep.ActionHalt
 		}
-		defer exportReader.Close()
+	}
 
-		tempDestinationPath := name + ".tmp"
-		outFile, err := os.OpenFile(tempDestinationPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	// Exports go to the object storage of the export destination instead of the output directory
+	var dest *s3Destination
+	if s.Destination.URL != "" {
+		var err error
+		dest, err = newS3Destination(ctx, s.Destination)
 		if err != nil {
-			ui.Error("Image temp file create failed: " + err.Error())
+			ui.Error("Export destination setup failed: " + err.Error())
 			state.Put("error", err)
 			return multistep.ActionHalt
 		}
+	}
 
-		progressTotal := int64(0)
-		if imageToExport.size > 0 {
-			progressTotal = imageToExport.size
-		}
-		trackedReader := ui.TrackProgress(name, 0, progressTotal, exportReader)
+	// All the downloads share a single progress bar
+	total := int64(0)
+	for _, image := range imageList {
+		total += image.size
+	}
+	progress := newTransferProgress(ui, fmt.Sprintf("%d image(s)", len(imageList)), total)
+	defer progress.Close()
+
+	// The first failed export cancels the others
+	exportCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-		copyDone := make(chan error, 1)
+	parallelism := max(s.Parallelism, 1)
+	sem := make(chan struct{}, parallelism)
+	errs := make([]error, len(imageList))
+	var wg sync.WaitGroup
+	for i, imageToExport := range imageList {
+		wg.Add(1)
 		go func() {
-			_, copyErr := io.Copy(outFile, trackedReader)
-			copyDone <- copyErr
-		}()
+			defer wg.Done()
 
-		select {
-		case copyErr := <-copyDone:
-			if copyErr != nil {
-				_ = outFile.Close()
-				_ = os.Remove(tempDestinationPath)
-				ui.Error("Image export copy failed: " + copyErr.Error())
-				state.Put("error", copyErr)
-				return multistep.ActionHalt
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-exportCtx.Done():
+				return
 			}
-			_ = trackedReader.Close()
 
-			if err := outFile.Close(); err != nil {
-				_ = os.Remove(tempDestinationPath)
-				ui.Error("Image temp file close failed: " + err.Error())
-				state.Put("error", err)
-				return multistep.ActionHalt
+			if err := s.exportImage(exportCtx, ui, state, d, dest, progress, imageToExport); err != nil {
+				errs[i] = err
+				cancel()
 			}
+		}()
+	}
+	wg.Wait()
 
-			fi, err := os.Stat(tempDestinationPath)
-			if err != nil {
-				_ = os.Remove(tempDestinationPath)
-				ui.Error("Image temp stat failed: " + err.Error())
-				state.Put("error", err)
-				return multistep.ActionHalt
-			}
+	if ctx.Err() != nil {
+		ui.Say("image export cancelled")
+		return multistep.ActionHalt
+	}
 
-			if imageToExport.size > 0 && fi.Size() != imageToExport.size {
-				_ = os.Remove(tempDestinationPath)
-				err := fmt.Errorf("image size mismatch: expected %d, got %d", imageToExport.size, fi.Size())
-				ui.Error(err.Error())
-				state.Put("error", err)
-				return multistep.ActionHalt
-			}
+	// Report the failure that cancelled the other exports, not their cancellation
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			ui.Error("Image export failed: " + err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+	}
 
-			finalName := name + ".img"
-			if err := os.Rename(tempDestinationPath, finalName); err != nil {
-				_ = os.Remove(tempDestinationPath)
-				ui.Error("Failed to rename image file: " + err.Error())
-				state.Put("error", err)
-				return multistep.ActionHalt
-			}
+	return multistep.ActionContinue
+}
 
-			ui.Say(fmt.Sprintf("Image %s exported", finalName))
+// exportImage exports an image to the output directory or the export destination.
+func (s *stepExportImage) exportImage(ctx context.Context, ui packer.Ui, state multistep.StateBag, d Driver, dest *s3Destination, progress *transferProgress, imageToExport imageArtefact) error {
+	name := imageToExport.name
+	open := func(ctx context.Context, offset int64) (io.ReadCloser, int64, error) {
+		return d.ExportImage(ctx, imageToExport.uuid, offset)
+	}
 
-		case <-sigChan:
-			_ = trackedReader.Close()
-			_ = outFile.Close()
+	// Raw images are streamed to the object storage, other formats are converted locally first
+	if dest != nil && s.Format == NutanixIdentifierExportFormatRaw {
+		ui.Say(fmt.Sprintf("Streaming image %s to %s...", name, s.Destination.URL))
+		return s.streamImage(ctx, ui, state, dest, progress, imageToExport, open)
+	}
+
+	finalName := exportPath(s.OutputDirectory, exportFileName(name, s.Format, s.Compression))
+
+	// Raw images are compressed while they are downloaded. Other formats are downloaded
+	// raw first, qemu-img needs a seekable file to convert.
+	tempDestinationPath := finalName + ".tmp"
+	downloadCompression := s.Compression
+	if s.Format != NutanixIdentifierExportFormatRaw {
+		tempDestinationPath = exportPath(s.OutputDirectory, name+".raw.tmp")
+		downloadCompression = ""
+	}
+
+	// Compressed downloads are checked against the checksum of the raw stream
+	var rawSum hash.Hash
+	if imageToExport.checksum != "" && downloadCompression != "" {
+		var err error
+		rawSum, err = newChecksumHash(imageToExport.checksumType)
+		if err != nil {
+			return err
+		}
+	}
+
+	ui.Say(fmt.Sprintf("Downloading image %s...", name))
+
+	written, err := downloadExport(ctx, ui, progress, name, tempDestinationPath, downloadCompression, s.Retries, rawSum, open)
+	if err != nil {
+		_ = os.Remove(tempDestinationPath)
+		return err
+	}
+
+	if imageToExport.size > 0 && written != imageToExport.size {
+		_ = os.Remove(tempDestinationPath)
+		return fmt.Errorf("image size mismatch: expected %d, got %d", imageToExport.size, written)
+	}
+
+	if rawSum != nil {
+		if actual := hex.EncodeToString(rawSum.Sum(nil)); !strings.EqualFold(actual, imageToExport.checksum) {
 			_ = os.Remove(tempDestinationPath)
-			ui.Say("image export cancelled")
-			return multistep.ActionHalt
+			return fmt.Errorf("verification failed: %s checksum mismatch: expected %s, got %s", imageToExport.checksumType, imageToExport.checksum, actual)
+		}
+	} else if imageToExport.checksum != "" {
+		if err := verifyFileChecksum(tempDestinationPath, imageToExport.checksumType, imageToExport.checksum); err != nil {
+			_ = os.Remove(tempDestinationPath)
+			return fmt.Errorf("verification failed: %s", err)
 		}
 	}
 
-	return multistep.ActionContinue
+	if s.Format != NutanixIdentifierExportFormatRaw {
+		ui.Say(fmt.Sprintf("Converting image %s to %s...", name, s.Format))
+		if err := s.convert(ctx, name, tempDestinationPath, finalName); err != nil {
+			return fmt.Errorf("conversion failed: %s", err)
+		}
+	} else if err := moveFile(tempDestinationPath, finalName); err != nil {
+		_ = os.Remove(tempDestinationPath)
+		return fmt.Errorf("failed to rename image file: %s", err)
+	}
+
+	if dest != nil {
+		ui.Say(fmt.Sprintf("Uploading image %s to %s...", finalName, s.Destination.URL))
+		return s.uploadImage(ctx, ui, state, dest, finalName, imageToExport)
+	}
+
+	if err := recordExport(state, finalName, imageToExport.uuid, s.Format, s.Compression); err != nil {
+		return fmt.Errorf("failed to record image export: %s", err)
+	}
+
+	ui.Say(fmt.Sprintf("Image %s exported", finalName))
+	return nil
+}
+
+// convert converts the downloaded raw image rawPath to the export format, compresses it if
+// needed and moves the result to finalName. rawPath is always removed. Each intermediate file is
+// removed as soon as the next one is written, so at most two copies of the image are on disk.
+func (s *stepExportImage) convert(ctx context.Context, name string, rawPath string, finalName string) error {
+	convertedPath := finalName + ".tmp"
+	if s.Compression != "" {
+		convertedPath = exportPath(s.OutputDirectory, exportFileName(name, s.Format, "")+".tmp")
+	}
+
+	err := convertImage(ctx, rawPath, convertedPath, s.Format)
+	_ = os.Remove(rawPath)
+	if err != nil {
+		return err
+	}
+
+	if s.Compression != "" {
+		err := compressFile(convertedPath, finalName+".tmp", s.Compression)
+		_ = os.Remove(convertedPath)
+		if err != nil {
+			return fmt.Errorf("error compressing image: %s", err)
+		}
+	}
+
+	if err := moveFile(finalName+".tmp", finalName); err != nil {
+		_ = os.Remove(finalName + ".tmp")
+		return err
+	}
+	return nil
+}
+
+// streamImage streams the download of a raw image to the export destination, compressed on the
+// fly, and checks the downloaded size and checksum.
+func (s *stepExportImage) streamImage(ctx context.Context, ui packer.Ui, state multistep.StateBag, dest *s3Destination, progress *transferProgress, image imageArtefact, open exportOpener) error {
+	name := exportFileName(image.name, s.Format, s.Compression)
+
+	reader := progress.reader(&contextReader{ctx: ctx, ReadCloser: &resumingReader{ctx: ctx, retries: s.Retries, open: open}})
+	defer reader.Close()
+
+	result, err := uploadExport(ctx, dest, name, reader, s.Compression, image.checksumType)
+	if err != nil {
+		return err
+	}
+
+	if image.size > 0 && result.read != image.size {
+		err = fmt.Errorf("image size mismatch: expected %d, got %d", image.size, result.read)
+	} else if image.checksum != "" && !strings.EqualFold(result.checksum, image.checksum) {
+		err = fmt.Errorf("%s checksum mismatch: expected %s, got %s", image.checksumType, image.checksum, result.checksum)
+	}
+	if err != nil {
+		if deleteErr := dest.delete(ctx, name); deleteErr != nil {
+			log.Printf("failed to delete %s: %s", dest.url(name), deleteErr)
+		}
+		return err
+	}
+
+	if err := recordS3Export(ctx, state, dest, exportedFile{
+		Name:        name,
+		SourceUUID:  image.uuid,
+		Size:        result.size,
+		Format:      s.Format,
+		Compression: s.Compression,
+		SHA256:      result.sha256,
+	}); err != nil {
+		return err
+	}
+
+	ui.Say(fmt.Sprintf("Image %s exported", dest.url(name)))
+	return nil
+}
+
+// uploadImage uploads an image exported to path to the export destination and removes path.
+func (s *stepExportImage) uploadImage(ctx context.Context, ui packer.Ui, state multistep.StateBag, dest *s3Destination, path string, image imageArtefact) error {
+	defer os.Remove(path)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	name := filepath.Base(path)
+	result, err := uploadExport(ctx, dest, name, f, "", "")
+	if err != nil {
+		return err
+	}
+
+	if err := recordS3Export(ctx, state, dest, exportedFile{
+		Name:        name,
+		SourceUUID:  image.uuid,
+		Size:        result.size,
+		Format:      s.Format,
+		Compression: s.Compression,
+		SHA256:      result.sha256,
+	}); err != nil {
+		return err
+	}
+
+	ui.Say(fmt.Sprintf("Image %s exported", dest.url(name)))
+	return nil
 }
 
 func (s *stepExportImage) Cleanup(state multistep.StateBag) {}
@@ -3,6 +3,7 @@ package nutanix
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
@@ -10,34 +11,115 @@ import (
 )
 
 type StepExportOVA struct {
-	VMName    string
-	OvaConfig OvaConfig
+	VMName          string
+	OvaConfig       OvaConfig
+	OutputDirectory string
+	Retries         int
+	Destination     ExportDestination
 }
 
 func (s *StepExportOVA) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	ui := state.Get("ui").(packer.Ui)
 	d := state.Get("driver").(Driver)
 
+	if _, ok := state.GetOk("ova_skip"); ok {
+		ui.Sayf("Skipping export of existing OVA %s", s.OvaConfig.Name)
+		return multistep.ActionContinue
+	}
+
+	ovaUUID := state.Get("ova_uuid").(string)
+
 	ui.Say(fmt.Sprintf("Exporting OVA for virtual machine %s...", s.VMName))
 
-	// ExportOVA now returns the path to the downloaded file
-	downloadedFilePath, err := d.ExportOVA(ctx, s.OvaConfig.Name)
+	open := func(ctx context.Context, offset int64) (io.ReadCloser, int64, error) {
+		return d.ExportOVA(ctx, ovaUUID, offset)
+	}
+
+	if s.Destination.URL != "" {
+		if err := s.streamOVA(ctx, ui, state, ovaUUID, open); err != nil {
+			ui.Error("OVA export failed: " + err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+		return s.deleteAfterExport(ctx, ui, state, d, ovaUUID)
+	}
+
+	finalName := exportPath(s.OutputDirectory, s.OvaConfig.Name+".ova")
+	tempDestinationPath := finalName + ".tmp"
+
+	progress := newTransferProgress(ui, s.OvaConfig.Name, 0)
+	_, err := downloadExport(ctx, ui, progress, s.OvaConfig.Name, tempDestinationPath, "", s.Retries, nil, open)
+	progress.Close()
 	if err != nil {
+		_ = os.Remove(tempDestinationPath)
 		ui.Error("OVA export failed: " + err.Error())
 		state.Put("error", err)
 		return multistep.ActionHalt
 	}
 
-	// Rename the downloaded file to the final OVA name
-	finalName := s.OvaConfig.Name + ".ova"
-	err = os.Rename(downloadedFilePath, finalName)
-	if err != nil {
+	if err := moveFile(tempDestinationPath, finalName); err != nil {
+		_ = os.Remove(tempDestinationPath)
 		ui.Error("Failed to rename OVA file: " + err.Error())
 		state.Put("error", err)
 		return multistep.ActionHalt
 	}
 
+	if err := recordExport(state, finalName, ovaUUID, "ova", ""); err != nil {
+		ui.Error("Failed to record OVA export: " + err.Error())
+		state.Put("error", err)
+		return multistep.ActionHalt
+	}
+
 	ui.Say(fmt.Sprintf("OVA exported as \"%s\"", finalName))
+
+	return s.deleteAfterExport(ctx, ui, state, d, ovaUUID)
+}
+
+// streamOVA streams the download of the OVA to the export destination.
+func (s *StepExportOVA) streamOVA(ctx context.Context, ui packer.Ui, state multistep.StateBag, ovaUUID string, open exportOpener) error {
+	dest, err := newS3Destination(ctx, s.Destination)
+	if err != nil {
+		return err
+	}
+
+	name := s.OvaConfig.Name + ".ova"
+	progress := newTransferProgress(ui, s.OvaConfig.Name, 0)
+	defer progress.Close()
+
+	reader := progress.reader(&contextReader{ctx: ctx, ReadCloser: &resumingReader{ctx: ctx, retries: s.Retries, open: open}})
+	defer reader.Close()
+
+	result, err := uploadExport(ctx, dest, name, reader, "", "")
+	if err != nil {
+		return err
+	}
+
+	if err := recordS3Export(ctx, state, dest, exportedFile{
+		Name:       name,
+		SourceUUID: ovaUUID,
+		Size:       result.size,
+		Format:     "ova",
+		SHA256:     result.sha256,
+	}); err != nil {
+		return err
+	}
+
+	ui.Say(fmt.Sprintf("OVA exported as \"%s\"", dest.url(name)))
+	return nil
+}
+
+// deleteAfterExport deletes the exported OVA from Prism Central when asked to.
+func (s *StepExportOVA) deleteAfterExport(ctx context.Context, ui packer.Ui, state multistep.StateBag, d Driver, ovaUUID string) multistep.StepAction {
+	if s.OvaConfig.DeleteAfterExport {
+		ui.Sayf("Deleting OVA %s from Prism Central...", s.OvaConfig.Name)
+		if err := d.DeleteOVA(ctx, ovaUUID); err != nil {
+			ui.Error("Failed to delete exported OVA: " + err.Error())
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+		state.Put("ova_deleted", true)
+	}
+
 	return multistep.ActionContinue
 }
 
@@ -0,0 +1,108 @@
+package nutanix
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// stepMediaChange ejects or inserts images on the CD-ROMs of the running VM for
+// all media_change entries of a given build stage.
+type stepMediaChange struct {
+	Config *Config
+	Stage  string
+}
+
+func (s *stepMediaChange) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	var changes []MediaChange
+	for _, change := range s.Config.MediaChanges {
+		if change.Stage == s.Stage {
+			changes = append(changes, change)
+		}
+	}
+
+	if len(changes) == 0 {
+		return multistep.ActionContinue
+	}
+
+	ui := state.Get("ui").(packer.Ui)
+	d := state.Get("driver").(Driver)
+	vmUUID := state.Get("vm_uuid").(string)
+
+	for _, change := range changes {
+		vm, err := d.GetVM(ctx, vmUUID)
+		if err != nil {
+			err = fmt.Errorf("%s: error getting virtual machine: %s", change.Name, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		// CdRoms are addressed by the device index assigned at VM creation, or the one of the
+		// source template or VM, which is only known here
+		cdromUUID := ""
+		loaded := false
+		matches := 0
+		for _, cdrom := range vm.VM().CdRoms {
+			if cdrom.DiskAddress != nil && cdrom.DiskAddress.Index != nil && *cdrom.DiskAddress.Index == change.CdromIndex && cdrom.ExtId != nil {
+				cdromUUID = *cdrom.ExtId
+				loaded = cdromLoaded(&cdrom)
+				matches++
+			}
+		}
+		if matches != 1 {
+			err := fmt.Errorf("%s: CD-ROM %d not found on virtual machine", change.Name, change.CdromIndex)
+			if matches > 1 {
+				err = fmt.Errorf("%s: CD-ROM %d matches CD-ROMs on more than one bus", change.Name, change.CdromIndex)
+			}
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		if loaded {
+			ui.Sayf("%s: ejecting CD-ROM %d...", change.Name, change.CdromIndex)
+			if err := d.EjectCdRom(ctx, vmUUID, cdromUUID); err != nil {
+				err = fmt.Errorf("%s: error ejecting CD-ROM %d: %s", change.Name, change.CdromIndex, err)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+		} else {
+			log.Printf("%s: CD-ROM %d is already empty", change.Name, change.CdromIndex)
+		}
+
+		if change.Action != NutanixIdentifierMediaChangeInsert {
+			continue
+		}
+
+		imageUUID := change.SourceImageUUID
+		if imageUUID == "" {
+			image, err := d.GetImage(ctx, change.SourceImageName)
+			if err != nil {
+				err = fmt.Errorf("%s: error finding image %s: %s", change.Name, change.SourceImageName, err)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+			imageUUID = image.UUID()
+		}
+
+		ui.Sayf("%s: inserting image %s in CD-ROM %d...", change.Name, imageUUID, change.CdromIndex)
+		if err := d.InsertCdRom(ctx, vmUUID, cdromUUID, imageUUID); err != nil {
+			err = fmt.Errorf("%s: error inserting image in CD-ROM %d: %s", change.Name, change.CdromIndex, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepMediaChange) Cleanup(state multistep.StateBag) {
+	// No cleanup needed for media change step
+}
@@ -0,0 +1,85 @@
+package nutanix
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	vmmModels "github.com/nutanix/ntnx-api-golang-clients/vmm-go-client/v4/models/vmm/v4/ahv/config"
+)
+
+// testCdRom returns a CD-ROM at index on the SATA bus, loaded with imageUUID, or with a backing
+// without data source when imageUUID is empty.
+func testCdRom(t *testing.T, id string, index int, imageUUID string) vmmModels.CdRom {
+	cdrom := vmmModels.NewCdRom()
+	cdrom.ExtId = &id
+	cdrom.DiskAddress = vmmModels.NewCdRomAddress()
+	cdrom.DiskAddress.BusType = vmmModels.CDROMBUSTYPE_SATA.Ref()
+	cdrom.DiskAddress.Index = &index
+	cdrom.BackingInfo = vmmModels.NewVmDisk()
+
+	if imageUUID != "" {
+		imageRef := vmmModels.NewImageReference()
+		imageRef.ImageExtId = &imageUUID
+		cdrom.BackingInfo.DataSource = vmmModels.NewDataSource()
+		cdrom.BackingInfo.DataSource.Reference = vmmModels.NewOneOfDataSourceReference()
+		if err := cdrom.BackingInfo.DataSource.Reference.SetValue(*imageRef); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return *cdrom
+}
+
+func TestStepMediaChange(t *testing.T) {
+	tests := []struct {
+		name      string
+		change    MediaChange
+		wantCalls []string
+		wantHalt  bool
+	}{
+		{
+			name:      "loaded cd-rom is ejected",
+			change:    MediaChange{Action: NutanixIdentifierMediaChangeEject, CdromIndex: 0},
+			wantCalls: []string{"GetVM vm", "EjectCdRom loaded"},
+		},
+		{
+			name:      "cd-rom with empty backing is not ejected",
+			change:    MediaChange{Action: NutanixIdentifierMediaChangeEject, CdromIndex: 1},
+			wantCalls: []string{"GetVM vm"},
+		},
+		{
+			name:      "image is inserted in empty cd-rom",
+			change:    MediaChange{Action: NutanixIdentifierMediaChangeInsert, CdromIndex: 1, SourceImageUUID: "drivers"},
+			wantCalls: []string{"GetVM vm", "InsertCdRom empty drivers"},
+		},
+		{
+			name:      "unknown cd-rom",
+			change:    MediaChange{Action: NutanixIdentifierMediaChangeEject, CdromIndex: 2},
+			wantCalls: []string{"GetVM vm"},
+			wantHalt:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := vmmModels.NewVm()
+			vm.CdRoms = []vmmModels.CdRom{testCdRom(t, "loaded", 0, "installer"), testCdRom(t, "empty", 1, "")}
+			d := &fakeDriver{vm: vm}
+			state := testState(t, d)
+			state.Put("vm_uuid", "vm")
+
+			tt.change.Stage = NutanixIdentifierMediaChangeBeforeProvision
+			config := &Config{VmConfig: VmConfig{MediaChanges: []MediaChange{tt.change}}}
+			step := &stepMediaChange{Config: config, Stage: NutanixIdentifierMediaChangeBeforeProvision}
+
+			action := step.Run(context.Background(), state)
+			if halted := action == multistep.ActionHalt; halted != tt.wantHalt {
+				t.Fatalf("got action %v, want halt %t", action, tt.wantHalt)
+			}
+			if !reflect.DeepEqual(d.calls, tt.wantCalls) {
+				t.Errorf("got calls %v, want %v", d.calls, tt.wantCalls)
+			}
+		})
+	}
+}
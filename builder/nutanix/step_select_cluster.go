@@ -0,0 +1,44 @@
+package nutanix
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// stepSelectCluster picks the cluster of the build among the candidates of the cluster selector,
+// before anything is created on it, when no cluster_name or cluster_uuid is set.
+type stepSelectCluster struct {
+	Config *Config
+}
+
+func (s *stepSelectCluster) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	ui := state.Get("ui").(packer.Ui)
+	d := state.Get("driver").(Driver)
+
+	ui.Say("Selecting cluster...")
+
+	cluster, err := d.SelectCluster(ctx, s.Config.VmConfig.ClusterSelector, s.Config.VmConfig, ui)
+	if err != nil {
+		err = fmt.Errorf("error selecting cluster: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	ui.Say(fmt.Sprintf("Selected cluster %s (%s), the one with the most free memory, then CPU and storage: %d MiB of free memory, %d GiB of free storage",
+		cluster.name, cluster.uuid, cluster.freeMemoryBytes/bytesPerMB, cluster.freeStorageBytes/bytesPerGB))
+
+	// The rest of the build runs on the selected cluster
+	s.Config.VmConfig.ClusterName = cluster.name
+	s.Config.VmConfig.ClusterUUID = cluster.uuid
+	state.Put("selected_cluster", cluster)
+
+	return multistep.ActionContinue
+}
+
+func (s *stepSelectCluster) Cleanup(state multistep.StateBag) {
+	// No cleanup needed for cluster selection step
+}
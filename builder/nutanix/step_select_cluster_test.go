@@ -0,0 +1,43 @@
+package nutanix
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+)
+
+func TestStepSelectCluster(t *testing.T) {
+	cluster := &clusterCapacity{uuid: "uuid-b", name: "cluster-b", freeMemoryBytes: 64 * bytesPerGB}
+	d := &fakeDriver{cluster: cluster}
+	state := testState(t, d)
+	config := &Config{VmConfig: VmConfig{ClusterSelector: ClusterSelector{Names: []string{"cluster-a", "cluster-b"}}}}
+
+	if action := (&stepSelectCluster{Config: config}).Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("got action %v, want continue: %v", action, state.Get("error"))
+	}
+
+	// The rest of the build runs on the selected cluster and the artifact records it
+	if config.VmConfig.ClusterName != "cluster-b" || config.VmConfig.ClusterUUID != "uuid-b" {
+		t.Errorf("got cluster %s (%s), want cluster-b (uuid-b)", config.VmConfig.ClusterName, config.VmConfig.ClusterUUID)
+	}
+	if selected, _ := state.Get("selected_cluster").(*clusterCapacity); selected != cluster {
+		t.Errorf("got selected cluster %v, want %v", selected, cluster)
+	}
+}
+
+func TestStepSelectClusterNoCandidate(t *testing.T) {
+	d := &fakeDriver{}
+	state := testState(t, d)
+	config := &Config{VmConfig: VmConfig{ClusterSelector: ClusterSelector{Names: []string{"cluster-a"}}}}
+
+	if action := (&stepSelectCluster{Config: config}).Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("got action %v, want halt", action)
+	}
+	if _, ok := state.GetOk("error"); !ok {
+		t.Error("no error in state")
+	}
+	if config.VmConfig.ClusterName != "" || config.VmConfig.ClusterUUID != "" {
+		t.Errorf("got cluster %s (%s), want none", config.VmConfig.ClusterName, config.VmConfig.ClusterUUID)
+	}
+}